@@ -0,0 +1,201 @@
+package controller
+
+import (
+	"errors"
+	"final_project-ftgo-h8/api/dto"
+	"final_project-ftgo-h8/api/model"
+	"final_project-ftgo-h8/api/repository"
+	"final_project-ftgo-h8/metrics"
+	"final_project-ftgo-h8/quantity"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+func (c *cartController) GetCart(ctx echo.Context) error {
+	// get user id
+	userId := ctx.Get("user").(model.User).Id
+
+	// find
+	cartItems, err := c.repository.FindCartItems(userId)
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 500, "failed to get cart", err.Error())
+	}
+
+	// detail
+	resDetail := dto.ResDetailGetCart{Items: []dto.ResDetailCartItem{}}
+	for _, v := range cartItems {
+		resItem := toResDetailCartItem(v)
+		resDetail.Items = append(resDetail.Items, resItem)
+		resDetail.TotalPrice += resItem.TotalPrice
+	}
+
+	return dto.WriteResponseWithDetail(ctx, 200, "cart informations", resDetail)
+}
+
+func (c *cartController) AddCartItem(ctx echo.Context) error {
+	// get user id
+	userId := ctx.Get("user").(model.User).Id
+
+	// bind
+	var reqBody dto.ReqBodyAddCartItem
+	err := ctx.Bind(&reqBody)
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 400, "failed to bind", err.Error())
+	}
+
+	// validate quantity
+	if reqBody.Quantity <= 0 {
+		return dto.WriteResponse(ctx, 400, "invalid to bind field quantity")
+	}
+
+	// add
+	cartItem, err := c.repository.AddCartItem(userId, reqBody.ProductId, reqBody.Quantity)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.WriteResponse(ctx, 404, "product not found")
+		}
+		if errors.Is(err, repository.ErrQuantityIncrement) || errors.Is(err, quantity.ErrTooLarge) || errors.Is(err, quantity.ErrTotalOverflow) {
+			return dto.WriteResponseWithDetail(ctx, 400, "invalid quantity", err.Error())
+		}
+		return dto.WriteResponseWithDetail(ctx, 500, "failed to add cart item", err.Error())
+	}
+
+	return dto.WriteResponseWithDetail(ctx, 201, "success add cart item", toResDetailCartItem(cartItem))
+}
+
+func (c *cartController) UpdateCartItem(ctx echo.Context) error {
+	// get user id
+	userId := ctx.Get("user").(model.User).Id
+
+	// get param path
+	productId, err := strconv.Atoi(ctx.Param("product_id"))
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 400, "failed to parse string to uinteger", err.Error())
+	}
+
+	// bind
+	var reqBody dto.ReqBodyUpdateCartItem
+	err = ctx.Bind(&reqBody)
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 400, "failed to bind", err.Error())
+	}
+
+	// validate quantity
+	if reqBody.Quantity <= 0 {
+		return dto.WriteResponse(ctx, 400, "invalid to bind field quantity")
+	}
+
+	// update
+	cartItem, err := c.repository.UpdateCartItem(userId, uint(productId), reqBody.Quantity)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.WriteResponse(ctx, 404, "cart item not found")
+		}
+		if errors.Is(err, repository.ErrQuantityIncrement) || errors.Is(err, quantity.ErrTooLarge) || errors.Is(err, quantity.ErrTotalOverflow) {
+			return dto.WriteResponseWithDetail(ctx, 400, "invalid quantity", err.Error())
+		}
+		return dto.WriteResponseWithDetail(ctx, 500, "failed to update cart item", err.Error())
+	}
+
+	return dto.WriteResponseWithDetail(ctx, 200, "success update cart item", toResDetailCartItem(cartItem))
+}
+
+func (c *cartController) DeleteCartItem(ctx echo.Context) error {
+	// get user id
+	userId := ctx.Get("user").(model.User).Id
+
+	// get param path
+	productId, err := strconv.Atoi(ctx.Param("product_id"))
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 400, "failed to parse string to uinteger", err.Error())
+	}
+
+	// delete
+	err = c.repository.DeleteCartItem(userId, uint(productId))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.WriteResponse(ctx, 404, "cart item not found")
+		}
+		return dto.WriteResponseWithDetail(ctx, 500, "failed to delete cart item", err.Error())
+	}
+
+	return dto.WriteResponse(ctx, 200, "success delete cart item")
+}
+
+func (c *cartController) Checkout(ctx echo.Context) error {
+	// get user id
+	userId := ctx.Get("user").(model.User).Id
+
+	// create one order per seller from the whole cart
+	orders, orderDetails, err := c.repository.Checkout(ctx.Request().Context(), userId)
+	if err != nil {
+		return checkoutError(ctx, err)
+	}
+	metrics.OrdersCreated.WithLabelValues("checkout").Add(float64(len(orders)))
+
+	// detail
+	resDetail := dto.ResDetailCheckout{
+		Orders:     []dto.ResDetailCheckoutOrder{},
+		Ordered_at: orders[0].OrderDate.Format(time.DateTime),
+	}
+	for _, order := range orders {
+		resOrder := dto.ResDetailCheckoutOrder{
+			OrderId:    order.Id,
+			SellerId:   order.SellerId,
+			Items:      []dto.ResDetailNewOrder{},
+			TotalPrice: order.TotalPrice,
+		}
+		for _, v := range orderDetails {
+			if v.OrderId != order.Id {
+				continue
+			}
+			resOrder.Items = append(resOrder.Items, dto.ResDetailNewOrder{
+				ProductName: v.Product.Name,
+				Quantity:    v.Quantity,
+				Unit:        v.Product.Unit,
+				Ordered_at:  resDetail.Ordered_at,
+				TotalPrice:  v.TotalPrice,
+			})
+		}
+		resDetail.Orders = append(resDetail.Orders, resOrder)
+		resDetail.TotalPrice += order.TotalPrice
+	}
+
+	return dto.WriteResponseWithDetail(ctx, 201, "success checkout", resDetail)
+}
+
+func toResDetailCartItem(cartItem model.CartItem) dto.ResDetailCartItem {
+	// cart quantities are checked against overflow when they are saved
+	totalPrice, _ := cartItem.Quantity.Total(cartItem.Product.Price)
+	return dto.ResDetailCartItem{
+		ProductId:    cartItem.ProductId,
+		ProductName:  cartItem.Product.Name,
+		ProductPrice: cartItem.Product.Price,
+		Quantity:     cartItem.Quantity,
+		Unit:         cartItem.Product.Unit,
+		TotalPrice:   totalPrice,
+	}
+}
+
+// checkoutError answers 4xx only for a cart the user can fix, everything else is a server error
+func checkoutError(ctx echo.Context, err error) error {
+	switch {
+	case errors.Is(err, repository.ErrCartEmpty), errors.Is(err, repository.ErrInsufficientFunds), errors.Is(err, quantity.ErrTotalOverflow):
+		return dto.WriteResponseWithDetail(ctx, 400, "failed to checkout", err.Error())
+	}
+
+	// product-service rejects invalid items and unavailable stock
+	if st, ok := status.FromError(err); ok {
+		switch st.Code() {
+		case codes.InvalidArgument, codes.NotFound, codes.FailedPrecondition:
+			return dto.ErrorResponse(ctx, err)
+		}
+	}
+
+	return dto.WriteResponseWithDetail(ctx, 500, "failed to checkout", err.Error())
+}
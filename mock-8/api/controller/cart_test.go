@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"final_project-ftgo-h8/api/model"
+	"final_project-ftgo-h8/api/repository"
+	"final_project-ftgo-h8/quantity"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// stubCart fails every checkout with err
+type stubCart struct {
+	repository.CartRepository
+	err error
+}
+
+func (s *stubCart) Checkout(ctx context.Context, userId uint) ([]model.Order, []model.OrderDetail, error) {
+	return nil, nil, s.err
+}
+
+func TestCheckoutErrorStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"empty cart", repository.ErrCartEmpty, http.StatusBadRequest},
+		{"insufficient funds", repository.ErrInsufficientFunds, http.StatusBadRequest},
+		{"total overflow", quantity.ErrTotalOverflow, http.StatusBadRequest},
+		{"invalid item", status.Error(codes.InvalidArgument, "quantity must be positive"), http.StatusBadRequest},
+		{"product gone", status.Error(codes.NotFound, "product not found"), http.StatusNotFound},
+		{"insufficient stock", status.Error(codes.FailedPrecondition, "Product stock is unavailable"), http.StatusConflict},
+		{"product-service down", status.Error(codes.Unavailable, "connection refused"), http.StatusInternalServerError},
+		{"database error", errors.New("driver: bad connection"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			rec := httptest.NewRecorder()
+			ctx := e.NewContext(httptest.NewRequest(http.MethodPost, "/cart/checkout", nil), rec)
+			ctx.Set("user", model.User{Id: 1})
+
+			c := &cartController{repository: &stubCart{err: tt.err}}
+			if err := c.Checkout(ctx); err != nil {
+				e.HTTPErrorHandler(err, ctx)
+			}
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"final_project-ftgo-h8/api/dto"
+	"final_project-ftgo-h8/pb"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (c *productController) GetAllCategories(ctx echo.Context) error {
+	allCategories, err := c.Service.GetAllCategory(ctx.Request().Context(), &pb.GetAllCategoryRequest{})
+	if err != nil {
+		return dto.ErrorResponse(ctx, err)
+	}
+
+	// build category tree
+	categories := map[string]*dto.ResDetailCategory{}
+	for _, v := range allCategories.GetCategories() {
+		categories[v.GetId()] = &dto.ResDetailCategory{
+			Id:       v.GetId(),
+			Name:     v.GetName(),
+			Slug:     v.GetSlug(),
+			ParentId: v.GetParentId(),
+			Children: []*dto.ResDetailCategory{},
+		}
+	}
+
+	roots := []*dto.ResDetailCategory{}
+	for _, v := range allCategories.GetCategories() {
+		category := categories[v.GetId()]
+		parent, ok := categories[v.GetParentId()]
+		if !ok {
+			roots = append(roots, category)
+			continue
+		}
+		parent.Children = append(parent.Children, category)
+	}
+
+	return dto.WriteResponseWithDetail(ctx, 200, "success get categories", roots)
+}
+
+func (c *productController) GetCategoryProducts(ctx echo.Context) error {
+	// bind query params
+	req, err := bindProductQuery(ctx)
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 400, "invalid query param", err.Error())
+	}
+
+	// check category exists
+	category, err := c.Service.GetCategory(ctx.Request().Context(), &pb.GetCategoryRequest{Slug: ctx.Param("slug")})
+	if err != nil {
+		return dto.ErrorResponse(ctx, err)
+	}
+
+	// products of the category and its sub categories
+	req.Category = category.GetSlug()
+	allProducts, err := c.Service.GetAllProduct(ctx.Request().Context(), req)
+	if err != nil {
+		return dto.ErrorResponse(ctx, err)
+	}
+
+	return writeProductPage(ctx, allProducts)
+}
+
+func (c *productController) CreateCategory(ctx echo.Context) error {
+	// bind
+	var reqBody dto.ReqBodyCategory
+	err := ctx.Bind(&reqBody)
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 400, "failed to bind", err.Error())
+	}
+
+	// create
+	category, err := c.Service.CreateCategory(userContext(ctx), &pb.CreateCategoryRequest{
+		Category: &pb.Category{
+			Name:     reqBody.Name,
+			Slug:     reqBody.Slug,
+			ParentId: reqBody.ParentId,
+		},
+	})
+	if err != nil {
+		return dto.ErrorResponse(ctx, err)
+	}
+
+	return dto.WriteResponseWithDetail(ctx, 201, "success create category", category)
+}
+
+func (c *productController) UpdateCategory(ctx echo.Context) error {
+	// bind
+	var reqBody dto.ReqBodyCategory
+	err := ctx.Bind(&reqBody)
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 400, "failed to bind", err.Error())
+	}
+
+	// update
+	category, err := c.Service.UpdateCategory(userContext(ctx), &pb.UpdateCategoryRequest{
+		Category: &pb.Category{
+			Id:       ctx.Param("id"),
+			Name:     reqBody.Name,
+			Slug:     reqBody.Slug,
+			ParentId: reqBody.ParentId,
+		},
+	})
+	if err != nil {
+		return dto.ErrorResponse(ctx, err)
+	}
+
+	return dto.WriteResponseWithDetail(ctx, 200, "success update category", category)
+}
+
+func (c *productController) DeleteCategory(ctx echo.Context) error {
+	_, err := c.Service.DeleteCategory(userContext(ctx), &pb.DeleteCategoryRequest{Id: ctx.Param("id")})
+	if err != nil {
+		return dto.ErrorResponse(ctx, err)
+	}
+
+	return ctx.NoContent(204)
+}
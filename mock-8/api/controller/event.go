@@ -0,0 +1,23 @@
+package controller
+
+import (
+	"context"
+	"final_project-ftgo-h8/api/publisher"
+	"final_project-ftgo-h8/event"
+	"log"
+)
+
+// publishEvent sends a notification for a change that is already saved,
+// a failed publish is logged and does not fail the request.
+func publishEvent(ctx context.Context, p publisher.Publisher, eventType string, payload interface{}) {
+	msgByte, err := event.Marshal(eventType, payload)
+	if err != nil {
+		log.Printf("failed to marshal %s event: %v", eventType, err)
+		return
+	}
+
+	err = p.PublishMessage(ctx, event.EmailNotificationQueue, msgByte)
+	if err != nil {
+		log.Printf("failed to publish %s event: %v", eventType, err)
+	}
+}
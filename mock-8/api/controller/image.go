@@ -0,0 +1,206 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"final_project-ftgo-h8/api/dto"
+	"final_project-ftgo-h8/helper"
+	"final_project-ftgo-h8/pb"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/disintegration/imaging"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// max size of one uploaded image
+	maxImageSize = 5 << 20
+	// max images uploaded in one request
+	maxImageFiles = 10
+	// max body of an upload request, the images and the multipart framing
+	MaxImageUploadSize = maxImageFiles*maxImageSize + 1<<20
+	// max width and height of one uploaded image, checked before it is decoded
+	maxImageDimension = 8000
+	// thumbnails fit in a square of this size
+	thumbnailSize = 320
+)
+
+// accepted image content types and their file extension
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+var (
+	errImageTooLarge  = fmt.Errorf("image must not be larger than %d MB", maxImageSize>>20)
+	errImageType      = errors.New("image must be a jpeg, png or gif")
+	errImageCorrupted = errors.New("image can not be decoded")
+	errImageDimension = fmt.Errorf("image must not be wider or higher than %d pixels", maxImageDimension)
+)
+
+// uploadedImage is a validated upload with its thumbnail
+type uploadedImage struct {
+	content     []byte
+	contentType string
+	thumbnail   []byte
+}
+
+func (c *productController) UploadProductImages(ctx echo.Context) error {
+	productID := ctx.Param("id")
+	if _, err := strconv.ParseUint(productID, 10, 64); err != nil {
+		return dto.WriteResponse(ctx, 400, "invalid product id")
+	}
+
+	// bind multipart files
+	form, err := ctx.MultipartForm()
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return dto.WriteResponse(ctx, 413, "request body too large")
+	}
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 400, "failed to bind", err.Error())
+	}
+	files := form.File["image"]
+	if len(files) == 0 {
+		return dto.WriteResponse(ctx, 400, "image file is required")
+	}
+	if len(files) > maxImageFiles {
+		return dto.WriteResponse(ctx, 400, fmt.Sprintf("at most %d images can be uploaded at once", maxImageFiles))
+	}
+
+	// check the size, type and dimensions of every image from its header first
+	contents := make([][]byte, 0, len(files))
+	for _, file := range files {
+		content, err := readImage(file)
+		if err != nil {
+			return dto.WriteResponseWithDetail(ctx, 400, "invalid image", fmt.Sprintf("%s: %s", file.Filename, err.Error()))
+		}
+		contents = append(contents, content)
+	}
+
+	// decode one image at a time, only its thumbnail is kept, nothing is stored until every image decoded
+	images := make([]uploadedImage, 0, len(files))
+	for i, content := range contents {
+		upload, err := decodeImage(content)
+		if err != nil {
+			return dto.WriteResponseWithDetail(ctx, 400, "invalid image", fmt.Sprintf("%s: %s", files[i].Filename, err.Error()))
+		}
+		images = append(images, upload)
+	}
+
+	var product *pb.Product
+	for _, upload := range images {
+		product, err = c.storeProductImage(ctx, productID, upload)
+		if err != nil {
+			return dto.ErrorResponse(ctx, err)
+		}
+	}
+
+	return ctx.JSON(201, echo.Map{
+		"message": "success upload",
+		"detail":  toResDetailProduct(product),
+	})
+}
+
+// readImage reads an uploaded file and checks its size, content type and dimensions without decoding it
+func readImage(file *multipart.FileHeader) ([]byte, error) {
+	if file.Size > maxImageSize {
+		return nil, errImageTooLarge
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	content, err := io.ReadAll(io.LimitReader(f, maxImageSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > maxImageSize {
+		return nil, errImageTooLarge
+	}
+
+	// the content decides the type, not the file name or the client header
+	if _, ok := imageExtensions[http.DetectContentType(content)]; !ok {
+		return nil, errImageType
+	}
+
+	// a small file can claim huge dimensions, read them from the header before decoding the pixels
+	config, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, errImageCorrupted
+	}
+	if config.Width > maxImageDimension || config.Height > maxImageDimension {
+		return nil, errImageDimension
+	}
+
+	return content, nil
+}
+
+// decodeImage decodes a checked image and renders its thumbnail, the decoded pixels are dropped afterwards
+func decodeImage(content []byte) (uploadedImage, error) {
+	img, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return uploadedImage{}, errImageCorrupted
+	}
+
+	var thumbnail bytes.Buffer
+	err = jpeg.Encode(&thumbnail, imaging.Fit(img, thumbnailSize, thumbnailSize, imaging.Lanczos), &jpeg.Options{Quality: 85})
+	if err != nil {
+		return uploadedImage{}, err
+	}
+
+	return uploadedImage{content: content, contentType: http.DetectContentType(content), thumbnail: thumbnail.Bytes()}, nil
+}
+
+// storeProductImage saves the image with its thumbnail and adds both to the product
+func (c *productController) storeProductImage(ctx echo.Context, productID string, upload uploadedImage) (*pb.Product, error) {
+	// store
+	name := fmt.Sprintf("products/%s/%s", productID, helper.GenerateRandomString(16))
+	imageKey := name + imageExtensions[upload.contentType]
+	thumbnailKey := name + "_thumb.jpg"
+
+	imageURL, err := c.Storage.Save(ctx.Request().Context(), imageKey, bytes.NewReader(upload.content), upload.contentType)
+	if err != nil {
+		return nil, err
+	}
+	thumbnailURL, err := c.Storage.Save(ctx.Request().Context(), thumbnailKey, bytes.NewReader(upload.thumbnail), "image/jpeg")
+	if err != nil {
+		c.deleteStoredFiles(ctx, imageKey)
+		return nil, err
+	}
+
+	// add to product
+	product, err := c.Service.AddProductImage(userContext(ctx), &pb.AddProductImageRequest{
+		ProductId:    productID,
+		Url:          imageURL,
+		ThumbnailUrl: thumbnailURL,
+	})
+	if err != nil {
+		c.deleteStoredFiles(ctx, imageKey, thumbnailKey)
+		return nil, err
+	}
+
+	return product, nil
+}
+
+func (c *productController) deleteStoredFiles(ctx echo.Context, keys ...string) {
+	for _, key := range keys {
+		err := c.Storage.Delete(ctx.Request().Context(), key)
+		if err != nil {
+			log.Printf("failed to delete stored file %s: %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// pngHeader is a png signature and IHDR chunk claiming the given size, without any pixels
+func pngHeader(width, height uint32) []byte {
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], width)
+	binary.BigEndian.PutUint32(ihdr[4:], height)
+	ihdr[8] = 8 // bit depth
+	ihdr[9] = 6 // rgba
+
+	var b bytes.Buffer
+	b.WriteString("\x89PNG\r\n\x1a\n")
+	binary.Write(&b, binary.BigEndian, uint32(len(ihdr)))
+	chunk := append([]byte("IHDR"), ihdr...)
+	b.Write(chunk)
+	binary.Write(&b, binary.BigEndian, crc32.ChecksumIEEE(chunk))
+	return b.Bytes()
+}
+
+func smallPNG(t *testing.T) []byte {
+	var b bytes.Buffer
+	if err := png.Encode(&b, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+// uploadRequest posts files as the image field of a multipart form
+func uploadRequest(t *testing.T, files ...[]byte) (echo.Context, *httptest.ResponseRecorder) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for i, content := range files {
+		part, err := w.CreateFormFile("image", strings.Repeat("a", i+1)+".png")
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(content)
+	}
+	w.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/product/1/images", &body)
+	req.Header.Set(echo.HeaderContentType, w.FormDataContentType())
+	rec := httptest.NewRecorder()
+	ctx := echo.New().NewContext(req, rec)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("1")
+	return ctx, rec
+}
+
+func TestUploadProductImagesRejectsTooManyFiles(t *testing.T) {
+	files := make([][]byte, maxImageFiles+1)
+	for i := range files {
+		files[i] = smallPNG(t)
+	}
+	ctx, rec := uploadRequest(t, files...)
+
+	// no storage or product-service, nothing may be stored
+	c := &productController{}
+	if err := c.UploadProductImages(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestUploadProductImagesRejectsHugeDimensions(t *testing.T) {
+	// a few bytes claiming a 100000 x 100000 image, decoding it would need 40 GB
+	ctx, rec := uploadRequest(t, smallPNG(t), pngHeader(100000, 100000))
+
+	c := &productController{}
+	if err := c.UploadProductImages(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if !strings.Contains(rec.Body.String(), errImageDimension.Error()) {
+		t.Errorf("body = %s, want the dimension error", rec.Body.String())
+	}
+}
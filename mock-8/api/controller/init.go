@@ -1,31 +1,51 @@
 package controller
 
 import (
+	"final_project-ftgo-h8/api/model"
 	"final_project-ftgo-h8/api/publisher"
 	"final_project-ftgo-h8/api/repository"
+	"final_project-ftgo-h8/api/storage"
 	"final_project-ftgo-h8/pb"
+
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// top-ups waiting for a midtrans notification
+type topUpStore interface {
+	InsertTopUp(orderId string, userId uint, amount int64, snapToken, redirectURL string) error
+	FindTopUpByOrderId(orderId string) (model.TopUp, error)
+	UpdateTopUpStatus(orderId, from, to string) (bool, error)
+}
+
 // user controller
 type userController struct{
 	repository repository.UserRepository
 	publisher publisher.Publisher
+	topUps topUpStore
+	secretSign []byte
+	midtransServerKey string
 }
 
-func NewUserController(r repository.UserRepository, p publisher.Publisher) UserController{
+func NewUserController(r repository.UserRepository, p publisher.Publisher, mongoDb *mongo.Database, secretSign string, midtransServerKey string) UserController{
 	return &userController{
 		repository: r,
 		publisher: p,
+		topUps: &mongoTopUps{collection: mongoDb.Collection("topup")},
+		secretSign: []byte(secretSign),
+		midtransServerKey: midtransServerKey,
 	}
 }
 
 // product controller
 type productController struct {
 	Service pb.ProductServiceClient
+	Storage storage.Storage
+	// users checks the seller an admin assigns a product to
+	Users repository.UserRepository
 }
 
-func NewProductController(pb pb.ProductServiceClient) ProductController {
-	return &productController{Service: pb}
+func NewProductController(pb pb.ProductServiceClient, s storage.Storage, u repository.UserRepository) ProductController {
+	return &productController{Service: pb, Storage: s, Users: u}
 }
 
 // order controller
@@ -37,4 +57,35 @@ func NewOrderController(r repository.OrderRepository) OrderController{
 	return &orderController{
 		repository: r,
 	}
+}
+
+// cart controller
+type cartController struct {
+	repository repository.CartRepository
+}
+
+func NewCartController(r repository.CartRepository) CartController {
+	return &cartController{
+		repository: r,
+	}
+}
+
+// seller controller
+type sellerController struct {
+	repository repository.SellerRepository
+}
+
+func NewSellerController(r repository.SellerRepository) SellerController {
+	return &sellerController{
+		repository: r,
+	}
+}
+
+// outbox controller
+type outboxController struct {
+	relay *publisher.OutboxRelay
+}
+
+func NewOutboxController(relay *publisher.OutboxRelay) OutboxController {
+	return &outboxController{relay: relay}
 }
\ No newline at end of file
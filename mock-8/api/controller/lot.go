@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"final_project-ftgo-h8/api/dto"
+	"final_project-ftgo-h8/pb"
+	"final_project-ftgo-h8/quantity"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (c *productController) AddStockLot(ctx echo.Context) error {
+	// bind
+	var reqBody dto.ReqBodyAddStockLot
+	err := ctx.Bind(&reqBody)
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 400, "failed to bind", err.Error())
+	}
+
+	// create
+	lot, err := c.Service.AddStockLot(userContext(ctx), &pb.AddStockLotRequest{
+		Lot: &pb.StockLot{
+			ProductId:  ctx.Param("id"),
+			LotNumber:  reqBody.LotNumber,
+			CatchDate:  reqBody.CatchDate,
+			OriginPort: reqBody.OriginPort,
+			Supplier:   reqBody.Supplier,
+			BestBefore: reqBody.BestBefore,
+			Quantity:   int64(reqBody.Quantity),
+		},
+	})
+	if err != nil {
+		return dto.ErrorResponse(ctx, err)
+	}
+
+	return dto.WriteResponseWithDetail(ctx, 201, "success add stock lot", toResDetailStockLot(lot))
+}
+
+func (c *productController) GetStockLots(ctx echo.Context) error {
+	// find
+	allLots, err := c.Service.GetAllStockLot(userContext(ctx), &pb.GetAllStockLotRequest{ProductId: ctx.Param("id")})
+	if err != nil {
+		return dto.ErrorResponse(ctx, err)
+	}
+
+	lots := []dto.ResDetailStockLot{}
+	for _, v := range allLots.GetLots() {
+		lots = append(lots, toResDetailStockLot(v))
+	}
+
+	return dto.WriteResponseWithDetail(ctx, 200, "success get stock lots", lots)
+}
+
+func toResDetailStockLot(lot *pb.StockLot) dto.ResDetailStockLot {
+	return dto.ResDetailStockLot{
+		Id:         lot.GetId(),
+		ProductId:  lot.GetProductId(),
+		LotNumber:  lot.GetLotNumber(),
+		CatchDate:  lot.GetCatchDate(),
+		OriginPort: lot.GetOriginPort(),
+		Supplier:   lot.GetSupplier(),
+		BestBefore: lot.GetBestBefore(),
+		Quantity:   quantity.Quantity(lot.GetQuantity()),
+		Remaining:  quantity.Quantity(lot.GetRemaining()),
+		Status:     lot.GetStatus(),
+	}
+}
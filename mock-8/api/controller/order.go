@@ -1,11 +1,17 @@
 package controller
 
 import (
+	"errors"
 	"final_project-ftgo-h8/api/dto"
 	"final_project-ftgo-h8/api/model"
+	"final_project-ftgo-h8/api/repository"
+	"final_project-ftgo-h8/metrics"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
 )
 
 func (c *orderController) NewOrder(ctx echo.Context) error{
@@ -22,21 +28,24 @@ func (c *orderController) NewOrder(ctx echo.Context) error{
 	// validate
 
 	// validate quantity
-	if reqBody.Quantity < 1 {
+	if reqBody.Quantity <= 0 {
 		return dto.WriteResponse(ctx,400,"invalid to bind field quantity")
 	}
 
 
 	// create order
-	orderDetail,err := c.repository.InsertOrderAndDetail(reqBody,userId)
+	orderDetail,err := c.repository.InsertOrderAndDetail(ctx.Request().Context(),reqBody,userId)
 	if err != nil {
 		return dto.WriteResponseWithDetail(ctx,400,"failed to order",err.Error())
 	}
 
+	metrics.OrdersCreated.WithLabelValues("order").Inc()
+
 	// detail
 	resDetail := dto.ResDetailNewOrder{
 		ProductName: orderDetail.Product.Name,
 		Quantity: orderDetail.Quantity,
+		Unit: orderDetail.Product.Unit,
 		Ordered_at: orderDetail.Order.OrderDate.Format(time.DateTime),
 		TotalPrice: orderDetail.TotalPrice,
 	}
@@ -59,10 +68,14 @@ func (c *orderController) GetOrders(ctx echo.Context) error{
 	var resDetails []dto.ResDetailGetOrder
 	for _,v := range orderDetails{
 		resDetail := dto.ResDetailGetOrder{
+			OrderId: v.OrderId,
+			Status: v.Order.Status,
+			ProductId: v.ProductId,
 			ProductName: v.Product.Name,
 			ProductPrice: v.Product.Price,
 			ProductDescription: v.Product.Description,
 			Quantity: v.Quantity,
+			Unit: v.Product.Unit,
 			TotalPrice: v.TotalPrice,
 			Ordered_at: v.Order.OrderDate.Format(time.DateTime),
 		}
@@ -70,4 +83,111 @@ func (c *orderController) GetOrders(ctx echo.Context) error{
 	}
 
 	return dto.WriteResponseWithDetail(ctx, 200, "order detail informations", resDetails)
-}
\ No newline at end of file
+}
+
+func (c *orderController) GetOrderStatusLogs(ctx echo.Context) error {
+	// get user id
+	userId := ctx.Get("user").(model.User).Id
+
+	// get param path
+	orderId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 400, "failed to parse string to uinteger", err.Error())
+	}
+
+	// find
+	orderStatusLogs, err := c.repository.FindOrderStatusLogs(uint(orderId), userId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.WriteResponse(ctx, 404, "order not found")
+		}
+		return dto.WriteResponseWithDetail(ctx, 500, "failed to get order status history", err.Error())
+	}
+
+	// detail
+	resDetails := []dto.ResDetailOrderStatusLog{}
+	for _, v := range orderStatusLogs {
+		resDetails = append(resDetails, dto.ResDetailOrderStatusLog{
+			Status:     v.Status,
+			Changed_at: v.ChangedAt.Format(time.DateTime),
+		})
+	}
+
+	return dto.WriteResponseWithDetail(ctx, 200, "order status history", resDetails)
+}
+
+func (c *orderController) CancelOrder(ctx echo.Context) error {
+	// get user id
+	userId := ctx.Get("user").(model.User).Id
+
+	// get param path
+	orderId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 400, "failed to parse string to uinteger", err.Error())
+	}
+
+	// cancel, return stock and refund
+	order, err := c.repository.CancelOrder(ctx.Request().Context(), uint(orderId), userId)
+	if err != nil {
+		return writeOrderStatusError(ctx, "failed to cancel order", err)
+	}
+
+	return dto.WriteResponseWithDetail(ctx, 200, "order has been cancelled and refunded", dto.ResDetailOrderStatus{
+		OrderId:    order.Id,
+		Status:     order.Status,
+		TotalPrice: order.TotalPrice,
+	})
+}
+
+func (c *orderController) UpdateOrderStatus(ctx echo.Context) error {
+	// get param path
+	orderId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 400, "failed to parse string to uinteger", err.Error())
+	}
+
+	// bind
+	var reqBody dto.ReqBodyUpdateOrderStatus
+	err = ctx.Bind(&reqBody)
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 400, "failed to bind", err.Error())
+	}
+
+	// validate status
+	status, ok := orderStatuses[strings.ToLower(reqBody.Status)]
+	if !ok {
+		return dto.WriteResponse(ctx, 400, "invalid status")
+	}
+
+	// update
+	order, err := c.repository.UpdateOrderStatus(ctx.Request().Context(), uint(orderId), status)
+	if err != nil {
+		return writeOrderStatusError(ctx, "failed to update order status", err)
+	}
+
+	return dto.WriteResponseWithDetail(ctx, 200, "order status has been updated", dto.ResDetailOrderStatus{
+		OrderId:    order.Id,
+		Status:     order.Status,
+		TotalPrice: order.TotalPrice,
+	})
+}
+
+var orderStatuses = map[string]string{
+	"pending":   model.OrderStatusPending,
+	"paid":      model.OrderStatusPaid,
+	"packed":    model.OrderStatusPacked,
+	"shipped":   model.OrderStatusShipped,
+	"delivered": model.OrderStatusDelivered,
+	"cancelled": model.OrderStatusCancelled,
+}
+
+func writeOrderStatusError(ctx echo.Context, message string, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return dto.WriteResponse(ctx, 404, "order not found")
+	case errors.Is(err, repository.ErrInvalidOrderStatusTransition):
+		return dto.WriteResponseWithDetail(ctx, 409, message, err.Error())
+	default:
+		return dto.WriteResponseWithDetail(ctx, 500, message, err.Error())
+	}
+}
@@ -0,0 +1,17 @@
+package controller
+
+import (
+	"final_project-ftgo-h8/api/dto"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (c *outboxController) GetOutboxStats(ctx echo.Context) error {
+	// find
+	stats, err := c.relay.Stats()
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 500, "failed to get outbox stats", err.Error())
+	}
+
+	return dto.WriteResponseWithDetail(ctx, 200, "success get outbox stats", stats)
+}
@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"errors"
+	"final_project-ftgo-h8/api/dto"
+	"final_project-ftgo-h8/api/repository"
+	"final_project-ftgo-h8/event"
+	"final_project-ftgo-h8/helper"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const passwordResetTTL = 30 * time.Minute
+
+func (c *userController) ForgotPassword(ctx echo.Context) error {
+	// bind
+	var reqBody dto.ReqBodyForgotPassword
+	err := ctx.Bind(&reqBody)
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 400, "failed to bind", err.Error())
+	}
+
+	// same answer whether the email exists or not
+	res := "if the email is registered, a password reset link has been sent"
+
+	// find by email
+	user, err := c.repository.FindUserByEmail(reqBody.Email)
+	if err != nil {
+		return dto.WriteResponse(ctx, 200, res)
+	}
+
+	// create single-use reset token, only its hash is stored
+	token, err := helper.GenerateSecureToken(32)
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 500, "failed to generate reset token", err.Error())
+	}
+	expiresAt := time.Now().Add(passwordResetTTL)
+	err = c.repository.InsertPasswordReset(user.Id, helper.HashToken(token), expiresAt)
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 500, "failed to create reset token", err.Error())
+	}
+
+	// marshal model
+	msgByte, err := event.Marshal(event.TypePasswordReset, event.PasswordReset{
+		Email:      user.Email,
+		ResetToken: token,
+		ExpiresAt:  expiresAt.Format(time.DateTime),
+	})
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 500, "failed to marshal object", err.Error())
+	}
+
+	// send token with email notification
+	err = c.publisher.PublishMessage(ctx.Request().Context(), event.EmailNotificationQueue, msgByte)
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 500, "failed to send email notification", err.Error())
+	}
+
+	return dto.WriteResponse(ctx, 200, res)
+}
+
+func (c *userController) ResetPassword(ctx echo.Context) error {
+	// bind
+	var reqBody dto.ReqBodyResetPassword
+	err := ctx.Bind(&reqBody)
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 400, "failed to bind", err.Error())
+	}
+
+	// validate
+	if reqBody.Token == "" || reqBody.Password == "" {
+		return dto.WriteResponse(ctx, 400, "token and password are required")
+	}
+
+	// hash
+	hash, err := helper.HashPassword(reqBody.Password)
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 400, "failed to hash", err.Error())
+	}
+
+	// update password and invalidate sessions
+	_, err = c.repository.ResetPassword(helper.HashToken(reqBody.Token), hash)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidPasswordResetToken) {
+			return dto.WriteResponseWithDetail(ctx, 400, "failed to reset password", err.Error())
+		}
+		return dto.WriteResponseWithDetail(ctx, 500, "failed to reset password", err.Error())
+	}
+
+	return dto.WriteResponse(ctx, 200, "your password has been reset, please login again")
+}
@@ -1,9 +1,17 @@
 package controller
 
 import (
+	"context"
+	"errors"
 	"final_project-ftgo-h8/pb"
 	"final_project-ftgo-h8/api/dto"
+	"final_project-ftgo-h8/api/model"
+	"final_project-ftgo-h8/helper"
+	"final_project-ftgo-h8/quantity"
+	"strconv"
+
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
 )
 
 func (c *productController) CreateProduct(ctx echo.Context) error {
@@ -15,33 +23,104 @@ func (c *productController) CreateProduct(ctx echo.Context) error {
 		})
 	}
 
+	if err := c.checkProductSeller(ctx, reqBody.SellerId); err != nil {
+		return err
+	}
+
 	req := pb.CreateProductRequest{
 		Product: &pb.Product{
-			Name:        reqBody.Name,
-			Description: reqBody.Description,
-			Price:       reqBody.Price,
-			Stock:       int32(reqBody.Stock),
+			Name:              reqBody.Name,
+			Description:       reqBody.Description,
+			Price:             reqBody.Price,
+			Unit:              reqBody.Unit,
+			OrderIncrement:    int64(reqBody.OrderIncrement),
+			LowStockThreshold: int64(reqBody.LowStockThreshold),
+			CategoryId:        reqBody.CategoryId,
+			Tags:              reqBody.Tags,
+			SellerId:          reqBody.SellerId,
 		},
 	}
 
-	newProduct, err := c.Service.CreateProduct(ctx.Request().Context(), &req)
+	newProduct, err := c.Service.CreateProduct(userContext(ctx), &req)
 	if err != nil {
 		return dto.ErrorResponse(ctx, err)
 	}
 
 	return ctx.JSON(201, echo.Map{
 		"message": "success create",
-		"detail":  newProduct,
+		"detail":  toResDetailProduct(newProduct),
 	})
 }
 
 func (c *productController) GetAllProducts(ctx echo.Context) error {
-    allProducts, err := c.Service.GetAllProduct(ctx.Request().Context(), &pb.GetAllProductRequest{})
+    // bind query params
+    req, err := bindProductQuery(ctx)
+    if err != nil {
+        return dto.WriteResponseWithDetail(ctx, 400, "invalid query param", err.Error())
+    }
+
+    allProducts, err := c.Service.GetAllProduct(ctx.Request().Context(), req)
     if err != nil {
         return dto.ErrorResponse(ctx, err)
     }
 
-    return ctx.JSON(200, allProducts)
+    return writeProductPage(ctx, allProducts)
+}
+
+// bindProductQuery binds the filter, sort and page query params of a product listing
+func bindProductQuery(ctx echo.Context) (*pb.GetAllProductRequest, error) {
+    var query dto.ReqQueryGetAllProducts
+    err := echo.QueryParamsBinder(ctx).
+        Int32("limit", &query.Limit).
+        Int32("offset", &query.Offset).
+        Int64("min_price", &query.MinPrice).
+        Int64("max_price", &query.MaxPrice).
+        Bool("in_stock", &query.InStock).
+        String("name", &query.Name).
+        String("sort", &query.Sort).
+        String("category", &query.Category).
+        String("tag", &query.Tag).
+        String("seller_id", &query.SellerId).
+        BindError()
+    if err != nil {
+        return nil, err
+    }
+
+    return &pb.GetAllProductRequest{
+        Limit:    query.Limit,
+        Offset:   query.Offset,
+        MinPrice: query.MinPrice,
+        MaxPrice: query.MaxPrice,
+        InStock:  query.InStock,
+        Name:     query.Name,
+        Sort:     query.Sort,
+        Category: query.Category,
+        Tag:      query.Tag,
+        SellerId: query.SellerId,
+    }, nil
+}
+
+// writeProductPage writes one page of products with its page metadata
+func writeProductPage(ctx echo.Context, allProducts *pb.GetAllProductResponse) error {
+    meta := dto.ResMetaPage{
+        Total:  allProducts.GetTotal(),
+        Limit:  allProducts.GetLimit(),
+        Offset: allProducts.GetOffset(),
+    }
+    if allProducts.GetHasNext() {
+        nextOffset := allProducts.GetNextOffset()
+        meta.NextOffset = &nextOffset
+    }
+
+    products := []dto.ResDetailProduct{}
+    for _, v := range allProducts.GetProducts() {
+        products = append(products, toResDetailProduct(v))
+    }
+
+    return ctx.JSON(200, echo.Map{
+        "products": products,
+        "meta":     meta,
+    })
 }
 
 func (c *productController) GetProduct(ctx echo.Context) error {
@@ -52,7 +131,7 @@ func (c *productController) GetProduct(ctx echo.Context) error {
 		return dto.ErrorResponse(ctx, err)
 	}
 
-	return ctx.JSON(200, product)
+	return ctx.JSON(200, toResDetailProduct(product))
 }
 
 func (c *productController) UpdateProduct(ctx echo.Context) error {
@@ -66,31 +145,92 @@ func (c *productController) UpdateProduct(ctx echo.Context) error {
 		})
 	}
 
+	if err := c.checkProductSeller(ctx, reqBody.SellerId); err != nil {
+		return err
+	}
+
 	req := pb.UpdateProductRequest{
 		Product: &pb.Product{
-			Id:          productID,
-			Name:        reqBody.Name,
-			Description: reqBody.Description,
-			Price:       reqBody.Price,
-			Stock:       int32(reqBody.Stock),
+			Id:             productID,
+			Name:              reqBody.Name,
+			Description:       reqBody.Description,
+			Price:             reqBody.Price,
+			Unit:              reqBody.Unit,
+			OrderIncrement:    int64(reqBody.OrderIncrement),
+			LowStockThreshold: int64(reqBody.LowStockThreshold),
+			CategoryId:        reqBody.CategoryId,
+			Tags:              reqBody.Tags,
+			SellerId:          reqBody.SellerId,
 		},
 	}
 
-	updatedProduct, err := c.Service.UpdateProduct(ctx.Request().Context(), &req)
+	updatedProduct, err := c.Service.UpdateProduct(userContext(ctx), &req)
 	if err != nil {
 		return dto.ErrorResponse(ctx, err)
 	}
 
-	return ctx.JSON(200, updatedProduct)
+	return ctx.JSON(200, toResDetailProduct(updatedProduct))
 }
 
 func (c *productController) DeleteProduct(ctx echo.Context) error {
 	productID := ctx.Param("id")
 
-	_, err := c.Service.DeleteProduct(ctx.Request().Context(), &pb.DeleteProductRequest{Id: productID})
+	_, err := c.Service.DeleteProduct(userContext(ctx), &pb.DeleteProductRequest{Id: productID})
 	if err != nil {
 		return dto.ErrorResponse(ctx, err)
 	}
 
 	return ctx.NoContent(204)
-}
\ No newline at end of file
+}
+
+// checkProductSeller rejects a seller_id set by an admin that is not the id of a seller,
+// product-service ignores the seller_id of sellers
+func (c *productController) checkProductSeller(ctx echo.Context, sellerId string) error {
+	if sellerId == "" || ctx.Get("user").(model.User).Role != "Admin" {
+		return nil
+	}
+
+	id, err := strconv.ParseUint(sellerId, 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(400, echo.Map{"message": "invalid seller id"})
+	}
+	seller, err := c.Users.FindUserById(uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(400, echo.Map{"message": "seller not found"})
+		}
+		return echo.NewHTTPError(500, echo.Map{"message": "failed to find seller", "detail": err.Error()})
+	}
+	if seller.Role != "Seller" {
+		return echo.NewHTTPError(400, echo.Map{"message": "user is not a seller"})
+	}
+	return nil
+}
+
+// request context carrying the logged in user for product-service
+func userContext(ctx echo.Context) context.Context {
+	user := ctx.Get("user").(model.User)
+	return helper.AppendUserMetadata(ctx.Request().Context(), user.Id, user.Role)
+}
+
+// toResDetailProduct converts the fixed-point stock of product-service to a decimal quantity
+func toResDetailProduct(product *pb.Product) dto.ResDetailProduct {
+	return dto.ResDetailProduct{
+		Id:                product.GetId(),
+		Name:              product.GetName(),
+		Description:       product.GetDescription(),
+		Price:             product.GetPrice(),
+		Stock:             quantity.Quantity(product.GetStock()),
+		Unit:              product.GetUnit(),
+		OrderIncrement:    quantity.Quantity(product.GetOrderIncrement()),
+		LowStockThreshold: quantity.Quantity(product.GetLowStockThreshold()),
+		CategoryId:        product.GetCategoryId(),
+		Tags:              product.GetTags(),
+		ImageUrls:         product.GetImageUrls(),
+		ThumbnailUrls:     product.GetThumbnailUrls(),
+		FreshestCatchDate: product.GetFreshestCatchDate(),
+		SellerId:          product.GetSellerId(),
+		RatingAverage:     product.GetRatingAverage(),
+		ReviewCount:       product.GetReviewCount(),
+	}
+}
@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"errors"
+	"final_project-ftgo-h8/api/dto"
+	"final_project-ftgo-h8/api/model"
+	"final_project-ftgo-h8/api/repository"
+	"final_project-ftgo-h8/pb"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+func (c *orderController) ReviewOrder(ctx echo.Context) error {
+	// get user id
+	userId := ctx.Get("user").(model.User).Id
+
+	// get param path
+	orderId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 400, "failed to parse string to uinteger", err.Error())
+	}
+
+	// bind
+	var reqBody dto.ReqBodyNewReview
+	err = ctx.Bind(&reqBody)
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 400, "failed to bind", err.Error())
+	}
+
+	// create review
+	review, err := c.repository.InsertReview(ctx.Request().Context(), uint(orderId), userId, reqBody)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return dto.WriteResponse(ctx, 404, "order line not found")
+		case errors.Is(err, repository.ErrOrderNotDelivered):
+			return dto.WriteResponseWithDetail(ctx, 409, "failed to review order", err.Error())
+		default:
+			return dto.ErrorResponse(ctx, err)
+		}
+	}
+
+	return dto.WriteResponseWithDetail(ctx, 201, "success review order", toResDetailReview(review))
+}
+
+func (c *productController) GetProductReviews(ctx echo.Context) error {
+	// bind query params
+	var query dto.ReqQueryGetReviews
+	err := echo.QueryParamsBinder(ctx).
+		Int32("limit", &query.Limit).
+		Int32("offset", &query.Offset).
+		BindError()
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 400, "invalid query param", err.Error())
+	}
+
+	// find
+	allReviews, err := c.Service.GetAllReview(ctx.Request().Context(), &pb.GetAllReviewRequest{
+		ProductId: ctx.Param("id"),
+		Limit:     query.Limit,
+		Offset:    query.Offset,
+	})
+	if err != nil {
+		return dto.ErrorResponse(ctx, err)
+	}
+
+	// page metadata
+	meta := dto.ResMetaPage{
+		Total:  allReviews.GetTotal(),
+		Limit:  allReviews.GetLimit(),
+		Offset: allReviews.GetOffset(),
+	}
+	if allReviews.GetHasNext() {
+		nextOffset := allReviews.GetNextOffset()
+		meta.NextOffset = &nextOffset
+	}
+
+	reviews := []dto.ResDetailReview{}
+	for _, v := range allReviews.GetReviews() {
+		reviews = append(reviews, toResDetailReview(v))
+	}
+
+	return ctx.JSON(200, echo.Map{
+		"reviews": reviews,
+		"meta":    meta,
+	})
+}
+
+func (c *productController) ModerateReview(ctx echo.Context) error {
+	// bind
+	var reqBody dto.ReqBodyModerateReview
+	err := ctx.Bind(&reqBody)
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 400, "failed to bind", err.Error())
+	}
+
+	// hide or show
+	review, err := c.Service.HideReview(userContext(ctx), &pb.HideReviewRequest{
+		Id:     ctx.Param("id"),
+		Hidden: reqBody.Hidden,
+	})
+	if err != nil {
+		return dto.ErrorResponse(ctx, err)
+	}
+
+	return dto.WriteResponseWithDetail(ctx, 200, "success moderate review", toResDetailReview(review))
+}
+
+func (c *productController) DeleteReview(ctx echo.Context) error {
+	// delete
+	review, err := c.Service.DeleteReview(userContext(ctx), &pb.DeleteReviewRequest{Id: ctx.Param("id")})
+	if err != nil {
+		return dto.ErrorResponse(ctx, err)
+	}
+
+	return dto.WriteResponseWithDetail(ctx, 200, "success delete review", toResDetailReview(review))
+}
+
+func toResDetailReview(review *pb.Review) dto.ResDetailReview {
+	return dto.ResDetailReview{
+		Id:        review.GetId(),
+		ProductId: review.GetProductId(),
+		UserId:    review.GetUserId(),
+		Rating:    review.GetRating(),
+		Body:      review.GetBody(),
+		Hidden:    review.GetHidden(),
+		CreatedAt: review.GetCreatedAt(),
+	}
+}
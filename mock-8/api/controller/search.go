@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"final_project-ftgo-h8/api/dto"
+	"final_project-ftgo-h8/pb"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (c *productController) SearchProducts(ctx echo.Context) error {
+	// bind query params
+	var query dto.ReqQuerySearchProducts
+	err := echo.QueryParamsBinder(ctx).
+		String("q", &query.Q).
+		Int32("limit", &query.Limit).
+		Int32("offset", &query.Offset).
+		BindError()
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 400, "invalid query param", err.Error())
+	}
+
+	// search
+	searchResult, err := c.Service.SearchProducts(ctx.Request().Context(), &pb.SearchProductsRequest{
+		Q:      query.Q,
+		Limit:  query.Limit,
+		Offset: query.Offset,
+	})
+	if err != nil {
+		return dto.ErrorResponse(ctx, err)
+	}
+
+	// page metadata
+	meta := dto.ResMetaPage{
+		Total:  searchResult.GetTotal(),
+		Limit:  searchResult.GetLimit(),
+		Offset: searchResult.GetOffset(),
+	}
+	if searchResult.GetHasNext() {
+		nextOffset := searchResult.GetNextOffset()
+		meta.NextOffset = &nextOffset
+	}
+
+	results := []dto.ResDetailSearchResult{}
+	for _, v := range searchResult.GetResults() {
+		results = append(results, dto.ResDetailSearchResult{
+			Product: toResDetailProduct(v.GetProduct()),
+			Rank:    v.GetRank(),
+			Snippet: v.GetSnippet(),
+		})
+	}
+
+	return ctx.JSON(200, echo.Map{
+		"results": results,
+		"meta":    meta,
+	})
+}
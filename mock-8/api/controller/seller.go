@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"errors"
+	"final_project-ftgo-h8/api/dto"
+	"final_project-ftgo-h8/api/model"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// order status a seller still has to act on
+var sellerFulfilmentStatuses = []string{model.OrderStatusPaid, model.OrderStatusPacked}
+
+func validateSellerProfile(profile dto.ReqBodySellerProfile) error {
+	if strings.TrimSpace(profile.StoreName) == "" {
+		return errors.New("store_name is required")
+	}
+	if strings.TrimSpace(profile.Location) == "" {
+		return errors.New("location is required")
+	}
+	if strings.TrimSpace(profile.BankName) == "" || strings.TrimSpace(profile.BankAccountNumber) == "" || strings.TrimSpace(profile.BankAccountName) == "" {
+		return errors.New("bank_name, bank_account_number and bank_account_name are required")
+	}
+	return nil
+}
+
+func (c *sellerController) GetProfile(ctx echo.Context) error {
+	// get user id
+	userId := ctx.Get("user").(model.User).Id
+
+	// find
+	profile, err := c.repository.FindSellerProfile(userId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.WriteResponse(ctx, 404, "seller profile not found")
+		}
+		return dto.WriteResponseWithDetail(ctx, 500, "failed to get seller profile", err.Error())
+	}
+
+	return dto.WriteResponseWithDetail(ctx, 200, "seller profile", profile)
+}
+
+func (c *sellerController) UpdateProfile(ctx echo.Context) error {
+	// get user id
+	userId := ctx.Get("user").(model.User).Id
+
+	// bind
+	var reqBody dto.ReqBodySellerProfile
+	err := ctx.Bind(&reqBody)
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 400, "failed to bind", err.Error())
+	}
+
+	// validate
+	err = validateSellerProfile(reqBody)
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 400, "invalid seller profile", err.Error())
+	}
+
+	// update
+	profile, err := c.repository.UpdateSellerProfile(userId, reqBody)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.WriteResponse(ctx, 404, "seller profile not found")
+		}
+		return dto.WriteResponseWithDetail(ctx, 500, "failed to update seller profile", err.Error())
+	}
+
+	return dto.WriteResponseWithDetail(ctx, 200, "success update seller profile", profile)
+}
+
+func (c *sellerController) GetOrders(ctx echo.Context) error {
+	// get user id
+	sellerId := ctx.Get("user").(model.User).Id
+
+	// orders to fulfil unless a status is asked for
+	statuses := sellerFulfilmentStatuses
+	if status := ctx.QueryParam("status"); status != "" {
+		statuses = []string{status}
+	}
+
+	// find
+	orders, orderDetails, err := c.repository.FindSellerOrders(sellerId, statuses)
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 500, "failed to get seller orders", err.Error())
+	}
+
+	// detail
+	resDetails := []dto.ResDetailSellerOrder{}
+	for _, order := range orders {
+		resDetail := dto.ResDetailSellerOrder{
+			OrderId:    order.Id,
+			Status:     order.Status,
+			Items:      []dto.ResDetailSellerOrderItem{},
+			TotalPrice: order.TotalPrice,
+			Ordered_at: order.OrderDate.Format(time.DateTime),
+		}
+		if order.Buyer != nil {
+			resDetail.BuyerName = order.Buyer.Name
+			resDetail.BuyerAddress = order.Buyer.Address
+			resDetail.BuyerPhone = order.Buyer.Phone
+		}
+		for _, v := range orderDetails {
+			if v.OrderId != order.Id {
+				continue
+			}
+			resDetail.Items = append(resDetail.Items, dto.ResDetailSellerOrderItem{
+				ProductId:   v.ProductId,
+				ProductName: v.Product.Name,
+				Quantity:    v.Quantity,
+				Unit:        v.Product.Unit,
+				TotalPrice:  v.TotalPrice,
+			})
+		}
+		resDetails = append(resDetails, resDetail)
+	}
+
+	return dto.WriteResponseWithDetail(ctx, 200, "seller orders", resDetails)
+}
@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"final_project-ftgo-h8/api/dto"
+	"final_project-ftgo-h8/pb"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (c *productController) SubscribeRestock(ctx echo.Context) error {
+	// subscribe the logged in user
+	subscription, err := c.Service.SubscribeRestock(userContext(ctx), &pb.RestockSubscriptionRequest{ProductId: ctx.Param("id")})
+	if err != nil {
+		return dto.ErrorResponse(ctx, err)
+	}
+
+	return dto.WriteResponseWithDetail(ctx, 201, "you will get an email when the product is back in stock", dto.ResDetailRestockSubscription{
+		ProductId: subscription.GetProductId(),
+		CreatedAt: subscription.GetCreatedAt(),
+	})
+}
+
+func (c *productController) UnsubscribeRestock(ctx echo.Context) error {
+	// unsubscribe the logged in user
+	subscription, err := c.Service.UnsubscribeRestock(userContext(ctx), &pb.RestockSubscriptionRequest{ProductId: ctx.Param("id")})
+	if err != nil {
+		return dto.ErrorResponse(ctx, err)
+	}
+
+	return dto.WriteResponseWithDetail(ctx, 200, "success unsubscribe from restock", dto.ResDetailRestockSubscription{
+		ProductId: subscription.GetProductId(),
+	})
+}
@@ -5,20 +5,62 @@ import "github.com/labstack/echo/v4"
 type UserController interface {
 	Register(echo.Context) error
 	Login(echo.Context) error
+	Refresh(echo.Context) error
+	Logout(echo.Context) error
+	ForgotPassword(echo.Context) error
+	ResetPassword(echo.Context) error
 	RegisterVerification(echo.Context) error
 	TopUp(ctx echo.Context) error
+	MidtransNotification(ctx echo.Context) error
+	GetWalletTransactions(ctx echo.Context) error
+	AdjustAmount(ctx echo.Context) error
 	GetInfo(ctx echo.Context) error
 }
 
 type ProductController interface {
 	CreateProduct(echo.Context) error
 	GetAllProducts(echo.Context) error
+	SearchProducts(echo.Context) error
 	GetProduct(echo.Context) error
 	UpdateProduct(echo.Context) error
 	DeleteProduct(ctx echo.Context) error
+	UploadProductImages(ctx echo.Context) error
+	AddStockLot(ctx echo.Context) error
+	GetStockLots(ctx echo.Context) error
+	GetProductReviews(ctx echo.Context) error
+	ModerateReview(ctx echo.Context) error
+	DeleteReview(ctx echo.Context) error
+	SubscribeRestock(ctx echo.Context) error
+	UnsubscribeRestock(ctx echo.Context) error
+	GetAllCategories(ctx echo.Context) error
+	GetCategoryProducts(ctx echo.Context) error
+	CreateCategory(ctx echo.Context) error
+	UpdateCategory(ctx echo.Context) error
+	DeleteCategory(ctx echo.Context) error
 }
 
 type OrderController interface{
 	NewOrder(ctx echo.Context) error
 	GetOrders(ctx echo.Context) error
-}
\ No newline at end of file
+	GetOrderStatusLogs(ctx echo.Context) error
+	CancelOrder(ctx echo.Context) error
+	UpdateOrderStatus(ctx echo.Context) error
+	ReviewOrder(ctx echo.Context) error
+}
+
+type CartController interface {
+	GetCart(ctx echo.Context) error
+	AddCartItem(ctx echo.Context) error
+	UpdateCartItem(ctx echo.Context) error
+	DeleteCartItem(ctx echo.Context) error
+	Checkout(ctx echo.Context) error
+}
+type SellerController interface {
+	GetProfile(ctx echo.Context) error
+	UpdateProfile(ctx echo.Context) error
+	GetOrders(ctx echo.Context) error
+}
+
+type OutboxController interface {
+	GetOutboxStats(ctx echo.Context) error
+}
@@ -2,20 +2,22 @@ package controller
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"final_project-ftgo-h8/api/dto"
 	"final_project-ftgo-h8/api/model"
+	"final_project-ftgo-h8/api/repository"
+	"final_project-ftgo-h8/event"
 	"final_project-ftgo-h8/helper"
-	"os"
+	"final_project-ftgo-h8/metrics"
+	"fmt"
 	"strconv"
+	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 	"github.com/midtrans/midtrans-go"
 	"github.com/midtrans/midtrans-go/snap"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 func (c *userController) Register(ctx echo.Context) error{
@@ -28,22 +30,37 @@ func (c *userController) Register(ctx echo.Context) error{
 	
 	// validate 
 
-	// validate and set role
+	// validate and set role, admins are never self registered
 	switch reqBody.Role{
-	case "Admin":
-		reqBody.Role = "Admin"
+	case "Admin", "admin":
+		return dto.WriteResponse(ctx,403,"admin accounts can not be registered")
 	case "User":
 		reqBody.Role = "User"
-	case "admin":
-		reqBody.Role = "Admin"
 	case "user":
 		reqBody.Role = "User"
+	case "Seller":
+		reqBody.Role = "Seller"
+	case "seller":
+		reqBody.Role = "Seller"
 	case "":
 		reqBody.Role = "User"
 	default:
 		return dto.WriteResponse(ctx,400,"invalid role")
 	}
 
+	// validate seller profile, only sellers have one
+	if reqBody.Role == "Seller" {
+		if reqBody.Seller == nil {
+			return dto.WriteResponse(ctx,400,"seller profile is required")
+		}
+		err = validateSellerProfile(*reqBody.Seller)
+		if err != nil {
+			return dto.WriteResponseWithDetail(ctx,400,"invalid seller profile",err.Error())
+		}
+	} else {
+		reqBody.Seller = nil
+	}
+
 	// hash
 	hash,err := helper.HashPassword(reqBody.Password)
 	if err != nil {
@@ -51,33 +68,11 @@ func (c *userController) Register(ctx echo.Context) error{
 	}
 	reqBody.Password = hash
 
-	// create user
-	user,err := c.repository.InsertUser(reqBody)
-	if err != nil {
-		return dto.WriteResponseWithDetail(ctx,400,"failed to create",err.Error())
-	}
-
-	// create user verification
+	// create user and verification code, the code is sent by the outbox relay
 	codeStr := helper.GenerateRandomString(20)
-	err = c.repository.InsertUserVerification(user.Id,codeStr)
+	user,err := c.repository.InsertUserAndVerification(reqBody,codeStr)
 	if err != nil {
-		return dto.WriteResponseWithDetail(ctx,500,"failed to create user verification code",err.Error())
-	}
-
-	// marshal model
-	msgByte,err := json.Marshal(dto.UserEmailVerification{
-		Email: user.Email,
-		UserId: user.Id,
-		VerificationCode: codeStr,
-	})
-	if err != nil {
-		return dto.WriteResponseWithDetail(ctx,500,"failed to marshal object",err.Error())
-	}
-
-	// send code with email notification
-	err = c.publisher.PublishMessage(context.Background(),"fishlink-email_notification",msgByte)
-	if err != nil {
-		return dto.WriteResponseWithDetail(ctx,500,"failed to send email notification",err.Error())
+		return dto.WriteResponseWithDetail(ctx,400,"failed to create",err.Error())
 	}
 
 	// omitempty
@@ -130,17 +125,92 @@ func (c *userController) Login(ctx echo.Context) error{
 	}
 
 	// generate jwt
-	secretsign := []byte(os.Getenv("SECRETSIGN"))
-	tokenString,err := helper.GenerateJWT(jwt.MapClaims{"user_id":user.Id},secretsign)
+	tokenString,err := helper.GenerateAccessToken(user.Id,c.secretSign)
 	if err != nil {
 		return dto.WriteResponseWithDetail(ctx,500,"failed to generate jwt",err.Error())
 	}
+
+	// generate refresh token, a new family per login
+	familyId,err := helper.GenerateSecureToken(16)
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx,500,"failed to generate refresh token",err.Error())
+	}
+	refreshToken,err := helper.GenerateSecureToken(32)
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx,500,"failed to generate refresh token",err.Error())
+	}
+	_,err = c.repository.InsertRefreshToken(user.Id,familyId,helper.HashToken(refreshToken),time.Now().Add(helper.RefreshTokenTTL))
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx,500,"failed to save refresh token",err.Error())
+	}
 	
-	return dto.WriteResponseWithDetail(ctx, 200, "login success", echo.Map{
-		"jwt":tokenString,
+	return dto.WriteResponseWithDetail(ctx, 200, "login success", dto.ResDetailToken{
+		Jwt: tokenString,
+		RefreshToken: refreshToken,
+		ExpiresIn: int64(helper.AccessTokenTTL.Seconds()),
 	})
 }
 
+func (c *userController) Refresh(ctx echo.Context) error{
+	// bind
+	var reqBody dto.ReqBodyRefreshToken
+	err := ctx.Bind(&reqBody)
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx,400,"failed to bind",err.Error())
+	}
+	if reqBody.RefreshToken == "" {
+		return dto.WriteResponse(ctx,400,"refresh token is required")
+	}
+
+	// rotate refresh token
+	refreshToken,err := helper.GenerateSecureToken(32)
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx,500,"failed to generate refresh token",err.Error())
+	}
+	newRefreshToken,err := c.repository.RotateRefreshToken(helper.HashToken(reqBody.RefreshToken),helper.HashToken(refreshToken),time.Now().Add(helper.RefreshTokenTTL))
+	if err != nil {
+		if errors.Is(err,repository.ErrInvalidRefreshToken) || errors.Is(err,repository.ErrRefreshTokenReused) {
+			return dto.WriteResponseWithDetail(ctx,401,"unauthorized user",err.Error())
+		}
+		return dto.WriteResponseWithDetail(ctx,500,"failed to refresh token",err.Error())
+	}
+
+	// generate jwt
+	tokenString,err := helper.GenerateAccessToken(newRefreshToken.UserId,c.secretSign)
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx,500,"failed to generate jwt",err.Error())
+	}
+
+	return dto.WriteResponseWithDetail(ctx, 200, "refresh success", dto.ResDetailToken{
+		Jwt: tokenString,
+		RefreshToken: refreshToken,
+		ExpiresIn: int64(helper.AccessTokenTTL.Seconds()),
+	})
+}
+
+func (c *userController) Logout(ctx echo.Context) error{
+	// bind
+	var reqBody dto.ReqBodyRefreshToken
+	err := ctx.Bind(&reqBody)
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx,400,"failed to bind",err.Error())
+	}
+	if reqBody.RefreshToken == "" {
+		return dto.WriteResponse(ctx,400,"refresh token is required")
+	}
+
+	// revoke the refresh token and every token rotated from the same login
+	err = c.repository.RevokeRefreshTokenFamily(helper.HashToken(reqBody.RefreshToken))
+	if err != nil {
+		if errors.Is(err,repository.ErrInvalidRefreshToken) {
+			return dto.WriteResponseWithDetail(ctx,401,"unauthorized user",err.Error())
+		}
+		return dto.WriteResponseWithDetail(ctx,500,"failed to logout",err.Error())
+	}
+
+	return dto.WriteResponse(ctx, 200, "logout success")
+}
+
 func (c *userController) GetInfo(ctx echo.Context) error{
 	user := ctx.Get("user").(model.User)
 	// omitempty
@@ -163,43 +233,114 @@ func (c *userController) TopUp(ctx echo.Context) error {
         return dto.WriteResponse(ctx, 400, "invalid amount")
     }
 
-    user, err := c.repository.FindUserById(userId)
-    if err != nil {
-        return dto.WriteResponse(ctx, 400, "user not found")
-    }
+    // Each top-up gets its own order id so Midtrans notifications can be matched back to it
+    orderId := fmt.Sprintf("TOPUP-%d-%d", userId, time.Now().UnixNano())
 
     // Obtain the Snap token and redirect URL using the provided amount
-    snapToken, redirectURL, err := GetSnapTokenAndRedirectURL(reqBody.Amount)
+    snapToken, redirectURL, err := c.GetSnapTokenAndRedirectURL(orderId, reqBody.Amount)
     if err != nil {
         return dto.WriteResponse(ctx, 400, "failed to create Snap transaction")
     }
 
-	// Save the top-up record to MongoDB
-    err = SaveToMongoDB(userId, reqBody.Amount, snapToken, redirectURL)
+    // Save the pending top-up record to MongoDB, the amount is credited by the payment notification
+    err = c.topUps.InsertTopUp(orderId, userId, reqBody.Amount, snapToken, redirectURL)
     if err != nil {
         return dto.WriteResponse(ctx, 400, "failed to save data to MongoDB")
     }
 
-    if _, err := c.repository.UpdateAmount(user, reqBody.Amount); err != nil {
-        return dto.WriteResponse(ctx, 400, "failed to top-up")
-    }
-
     response := map[string]interface{}{
+        "orderId":     orderId,
         "snapToken":   snapToken,
         "redirectURL": redirectURL,
-        "message":     "top-up successful",
+        "message":     "top-up created, your balance will be updated after payment",
     }
     return ctx.JSON(200, response)
 }
 
-func GetSnapTokenAndRedirectURL(amount int64) (string, string, error) {
+func (c *userController) MidtransNotification(ctx echo.Context) error {
+    var reqBody dto.ReqBodyMidtransNotification
+    err := ctx.Bind(&reqBody)
+    if err != nil {
+        return dto.WriteResponseWithDetail(ctx, 400, "invalid request body", err.Error())
+    }
+
+    // Verify the notification comes from Midtrans
+    if !helper.VerifyMidtransSignature(reqBody.OrderId, reqBody.StatusCode, reqBody.GrossAmount, c.midtransServerKey, reqBody.SignatureKey) {
+        return dto.WriteResponse(ctx, 401, "invalid signature key")
+    }
+
+    // Look up the pending top-up
+    topUp, err := c.topUps.FindTopUpByOrderId(reqBody.OrderId)
+    if err != nil {
+        if errors.Is(err, mongo.ErrNoDocuments) {
+            return dto.WriteResponse(ctx, 404, "top-up not found")
+        }
+        return dto.WriteResponseWithDetail(ctx, 500, "failed to find top-up", err.Error())
+    }
+
+    grossAmount, err := strconv.ParseFloat(reqBody.GrossAmount, 64)
+    if err != nil || int64(grossAmount) != topUp.Amount {
+        return dto.WriteResponse(ctx, 400, "gross amount does not match top-up amount")
+    }
+
+    switch reqBody.TransactionStatus {
+    case "settlement", "capture":
+        // Card captures flagged by fraud detection stay pending until Midtrans accepts them
+        if reqBody.TransactionStatus == "capture" && reqBody.FraudStatus != "" && reqBody.FraudStatus != "accept" {
+            return dto.WriteResponse(ctx, 200, "notification received")
+        }
+
+        if topUp.Status == TopUpStatusFailed {
+            return dto.WriteResponse(ctx, 200, "notification already processed")
+        }
+
+        // Credit the wallet first, the top-up id makes the credit happen once across retried notifications
+        user, credited, err := c.repository.CreditTopUp(topUp.UserId, topUp.OrderId, topUp.Amount)
+        if err != nil {
+            return dto.WriteResponseWithDetail(ctx, 500, "failed to top-up", err.Error())
+        }
+
+        // A retry finishes a top-up credited before a crash
+        _, err = c.topUps.UpdateTopUpStatus(topUp.OrderId, TopUpStatusPending, TopUpStatusSuccess)
+        if err != nil {
+            return dto.WriteResponseWithDetail(ctx, 500, "failed to update top-up", err.Error())
+        }
+        if !credited {
+            return dto.WriteResponse(ctx, 200, "notification already processed")
+        }
+
+        metrics.TopUps.WithLabelValues(TopUpStatusSuccess).Inc()
+        metrics.TopUpAmount.Add(float64(topUp.Amount))
+
+        publishEvent(ctx.Request().Context(), c.publisher, event.TypeTopUpSuccess, event.TopUpSuccess{
+            Email:   user.Email,
+            Name:    user.Name,
+            TopUpId: topUp.OrderId,
+            Amount:  topUp.Amount,
+            Balance: user.Amount,
+        })
+    case "deny", "expire", "cancel":
+        updated, err := c.topUps.UpdateTopUpStatus(topUp.OrderId, TopUpStatusPending, TopUpStatusFailed)
+        if err != nil {
+            return dto.WriteResponseWithDetail(ctx, 500, "failed to update top-up", err.Error())
+        }
+        if updated {
+            metrics.TopUps.WithLabelValues(TopUpStatusFailed).Inc()
+        }
+    }
+
+    return dto.WriteResponse(ctx, 200, "notification received")
+}
+
+func (c *userController) GetSnapTokenAndRedirectURL(orderId string, amount int64) (string, string, error) {
     // Initialize a Snap client
     snapClient := snap.Client{}
-    snapClient.New("SB-Mid-server-AKGOfC3ib5bSAq230AZ5gsjQ", midtrans.Sandbox)
+    snapClient.New(c.midtransServerKey, midtrans.Sandbox)
 
     // Create a Snap request with the provided amount
     req := &snap.Request{
         TransactionDetails: midtrans.TransactionDetails{
+            OrderID:  orderId,
             GrossAmt: amount,
         },
         CreditCard: &snap.CreditCardDetails{
@@ -221,31 +362,61 @@ func GetSnapTokenAndRedirectURL(amount int64) (string, string, error) {
     return snapToken, redirectURL, nil
 }
 
-func SaveToMongoDB(userId uint, amount int64, snapToken, redirectURL string) error {
-    // Connect to MongoDB
-    clientOptions := options.Client().ApplyURI("mongodb://localhost:27017")
-    client, err := mongo.Connect(context.TODO(), clientOptions)
-    if err != nil {
-        return err
-    }
-    defer client.Disconnect(context.TODO())
+// top-up status
+const (
+    TopUpStatusPending = "pending"
+    TopUpStatusSuccess = "success"
+    TopUpStatusFailed  = "failed"
+)
 
-    // Access the MongoDB collection
-    collection := client.Database("fishlink").Collection("topup")
+// mongoTopUps keeps top-ups in the mongo topup collection
+type mongoTopUps struct {
+    collection *mongo.Collection
+}
 
+func (s *mongoTopUps) InsertTopUp(orderId string, userId uint, amount int64, snapToken, redirectURL string) error {
     // Create a document to insert
     data := bson.M{
+        "orderId":     orderId,
         "userId":      userId,
         "amount":      amount,
         "snapToken":   snapToken,
         "redirectURL": redirectURL,
+        "status":      TopUpStatusPending,
+        "createdAt":   time.Now(),
     }
 
     // Insert the document into the collection
-    _, err = collection.InsertOne(context.TODO(), data)
+    _, err := s.collection.InsertOne(context.TODO(), data)
     if err != nil {
         return err
     }
 
     return nil
-}
\ No newline at end of file
+}
+
+func (s *mongoTopUps) FindTopUpByOrderId(orderId string) (model.TopUp, error) {
+    // Find the top-up by its order id
+    var topUp model.TopUp
+    err := s.collection.FindOne(context.TODO(), bson.M{"orderId": orderId}).Decode(&topUp)
+    if err != nil {
+        return model.TopUp{}, err
+    }
+
+    return topUp, nil
+}
+
+// UpdateTopUpStatus changes the top-up status only when it is still in the from status,
+// and reports whether the document was changed.
+func (s *mongoTopUps) UpdateTopUpStatus(orderId, from, to string) (bool, error) {
+    // Update the status
+    result, err := s.collection.UpdateOne(context.TODO(),
+        bson.M{"orderId": orderId, "status": from},
+        bson.M{"$set": bson.M{"status": to, "updatedAt": time.Now()}},
+    )
+    if err != nil {
+        return false, err
+    }
+
+    return result.ModifiedCount == 1, nil
+}
@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"final_project-ftgo-h8/api/dto"
+	"final_project-ftgo-h8/api/model"
+	"final_project-ftgo-h8/api/repository"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestRegisterRejectsAdminRole(t *testing.T) {
+	for _, role := range []string{"Admin", "admin"} {
+		t.Run(role, func(t *testing.T) {
+			body := `{"name":"mallory","email":"mallory@example.com","password":"secret","role":"` + role + `"}`
+			req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			// no repository, the request must be rejected before any user is stored
+			c := &userController{}
+			if err := c.Register(echo.New().NewContext(req, rec)); err != nil {
+				t.Fatal(err)
+			}
+			if rec.Code != http.StatusForbidden {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusForbidden)
+			}
+		})
+	}
+}
+
+// stubTopUps keeps top-ups in memory
+type stubTopUps map[string]*model.TopUp
+
+func (s stubTopUps) InsertTopUp(orderId string, userId uint, amount int64, snapToken, redirectURL string) error {
+	s[orderId] = &model.TopUp{OrderId: orderId, UserId: userId, Amount: amount, Status: TopUpStatusPending}
+	return nil
+}
+
+func (s stubTopUps) FindTopUpByOrderId(orderId string) (model.TopUp, error) {
+	return *s[orderId], nil
+}
+
+func (s stubTopUps) UpdateTopUpStatus(orderId, from, to string) (bool, error) {
+	if s[orderId].Status != from {
+		return false, nil
+	}
+	s[orderId].Status = to
+	return true, nil
+}
+
+// stubWallet credits a top-up id once like the wallet_transactions unique index
+type stubWallet struct {
+	repository.UserRepository
+	user     model.User
+	credited map[string]bool
+}
+
+func (s *stubWallet) CreditTopUp(userId uint, topUpId string, amount int64) (model.User, bool, error) {
+	if s.credited[topUpId] {
+		return s.user, false, nil
+	}
+	s.credited[topUpId] = true
+	s.user.Amount += amount
+	return s.user, true, nil
+}
+
+type stubPublisher struct {
+	messages int
+}
+
+func (s *stubPublisher) PublishMessage(ctx context.Context, queueName string, msg []byte) error {
+	s.messages++
+	return nil
+}
+
+func TestMidtransNotificationReplayCreditsOnce(t *testing.T) {
+	const serverKey = "SB-Mid-server-test"
+	topUps := stubTopUps{}
+	topUps.InsertTopUp("TOPUP-1", 1, 50000, "", "")
+	wallet := &stubWallet{user: model.User{Id: 1, Amount: 1000}, credited: map[string]bool{}}
+	pub := &stubPublisher{}
+	c := &userController{repository: wallet, publisher: pub, topUps: topUps, midtransServerKey: serverKey}
+
+	hash := sha512.Sum512([]byte("TOPUP-1" + "200" + "50000.00" + serverKey))
+	notification := `{"order_id":"TOPUP-1","status_code":"200","gross_amount":"50000.00","transaction_status":"settlement","signature_key":"` + hex.EncodeToString(hash[:]) + `"}`
+
+	// midtrans retries a notification until it gets a 200, the replay must not credit again
+	for i, want := range []string{"notification received", "notification already processed"} {
+		req := httptest.NewRequest(http.MethodPost, "/payments/midtrans/notification", strings.NewReader(notification))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		if err := c.MidtransNotification(echo.New().NewContext(req, rec)); err != nil {
+			t.Fatal(err)
+		}
+
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("notification %d: %d %s, want 200 %q", i+1, rec.Code, rec.Body.String(), want)
+		}
+	}
+
+	if wallet.user.Amount != 51000 {
+		t.Errorf("amount = %d, want 51000", wallet.user.Amount)
+	}
+	if pub.messages != 1 {
+		t.Errorf("published %d top-up events, want 1", pub.messages)
+	}
+	if topUps["TOPUP-1"].Status != TopUpStatusSuccess {
+		t.Errorf("top-up status = %s, want %s", topUps["TOPUP-1"].Status, TopUpStatusSuccess)
+	}
+}
+
+func TestMidtransNotificationRejectsBadSignature(t *testing.T) {
+	topUps := stubTopUps{}
+	topUps.InsertTopUp("TOPUP-1", 1, 50000, "", "")
+	wallet := &stubWallet{credited: map[string]bool{}}
+	c := &userController{repository: wallet, publisher: &stubPublisher{}, topUps: topUps, midtransServerKey: "SB-Mid-server-test"}
+
+	for _, signature := range []string{"", "deadbeef"} {
+		body, _ := json.Marshal(dto.ReqBodyMidtransNotification{
+			OrderId:           "TOPUP-1",
+			StatusCode:        "200",
+			GrossAmount:       "50000.00",
+			TransactionStatus: "settlement",
+			SignatureKey:      signature,
+		})
+		req := httptest.NewRequest(http.MethodPost, "/payments/midtrans/notification", strings.NewReader(string(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		if err := c.MidtransNotification(echo.New().NewContext(req, rec)); err != nil {
+			t.Fatal(err)
+		}
+
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("signature %q: status = %d, want %d", signature, rec.Code, http.StatusUnauthorized)
+		}
+	}
+	if len(wallet.credited) != 0 {
+		t.Errorf("credited %v, want nothing", wallet.credited)
+	}
+}
@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"errors"
+	"final_project-ftgo-h8/api/dto"
+	"final_project-ftgo-h8/api/model"
+	"final_project-ftgo-h8/api/repository"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultWalletTransactionLimit = 20
+	maxWalletTransactionLimit     = 100
+)
+
+func (c *userController) GetWalletTransactions(ctx echo.Context) error {
+	// get user id
+	userId := ctx.Get("user").(model.User).Id
+
+	// bind query params
+	var query dto.ReqQueryGetWalletTransactions
+	err := echo.QueryParamsBinder(ctx).
+		Int("limit", &query.Limit).
+		Int("offset", &query.Offset).
+		Time("from", &query.From, time.DateOnly).
+		Time("to", &query.To, time.DateOnly).
+		BindError()
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 400, "invalid query param", err.Error())
+	}
+
+	// validate page
+	if query.Limit == 0 {
+		query.Limit = defaultWalletTransactionLimit
+	}
+	if query.Limit < 0 || query.Limit > maxWalletTransactionLimit || query.Offset < 0 {
+		return dto.WriteResponse(ctx, 400, "invalid limit or offset")
+	}
+
+	// to date is inclusive
+	if !query.To.IsZero() {
+		query.To = query.To.AddDate(0, 0, 1)
+	}
+
+	// find
+	walletTransactions, total, err := c.repository.FindWalletTransactions(userId, repository.WalletTransactionFilter{
+		Limit:  query.Limit,
+		Offset: query.Offset,
+		From:   query.From,
+		To:     query.To,
+	})
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 500, "failed to get wallet transactions", err.Error())
+	}
+
+	// detail
+	resDetails := []dto.ResDetailWalletTransaction{}
+	for _, v := range walletTransactions {
+		resDetails = append(resDetails, dto.ResDetailWalletTransaction{
+			Type:         v.Type,
+			Amount:       v.Amount,
+			BalanceAfter: v.BalanceAfter,
+			OrderId:      v.OrderId,
+			TopUpId:      v.TopUpId,
+			Note:         v.Note,
+			Created_at:   v.CreatedAt.Format(time.DateTime),
+		})
+	}
+
+	// page metadata
+	meta := dto.ResMetaPage{
+		Total:  total,
+		Limit:  int32(query.Limit),
+		Offset: int32(query.Offset),
+	}
+	if int64(query.Offset+len(walletTransactions)) < total {
+		nextOffset := int32(query.Offset + len(walletTransactions))
+		meta.NextOffset = &nextOffset
+	}
+
+	return dto.WriteResponseWithDetail(ctx, 200, "wallet transaction history", echo.Map{
+		"transactions": resDetails,
+		"meta":         meta,
+	})
+}
+
+func (c *userController) AdjustAmount(ctx echo.Context) error {
+	// get param path
+	userId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 400, "failed to parse string to uinteger", err.Error())
+	}
+
+	// bind
+	var reqBody dto.ReqBodyWalletAdjustment
+	err = ctx.Bind(&reqBody)
+	if err != nil {
+		return dto.WriteResponseWithDetail(ctx, 400, "failed to bind", err.Error())
+	}
+
+	// validate
+	if reqBody.Amount == 0 || reqBody.Note == "" {
+		return dto.WriteResponse(ctx, 400, "amount and note are required")
+	}
+
+	// update
+	user, err := c.repository.UpdateAmount(uint(userId), model.WalletTransaction{
+		Type:   model.WalletTransactionAdjustment,
+		Amount: reqBody.Amount,
+		Note:   reqBody.Note,
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dto.WriteResponse(ctx, 404, "user not found")
+		}
+		return dto.WriteResponseWithDetail(ctx, 400, "failed to adjust amount", err.Error())
+	}
+
+	return dto.WriteResponseWithDetail(ctx, 200, "user amount has been adjusted", echo.Map{
+		"amount": user.Amount,
+	})
+}
@@ -0,0 +1,40 @@
+package dto
+
+import "final_project-ftgo-h8/quantity"
+
+type ReqBodyAddCartItem struct {
+	ProductId uint `json:"product_id"`
+	Quantity  quantity.Quantity `json:"quantity"`
+}
+
+type ReqBodyUpdateCartItem struct {
+	Quantity quantity.Quantity `json:"quantity"`
+}
+
+type ResDetailCartItem struct {
+	ProductId    uint   `json:"product_id"`
+	ProductName  string `json:"product_name"`
+	ProductPrice int64  `json:"product_price"`
+	Quantity     quantity.Quantity `json:"quantity"`
+	Unit         string `json:"unit"`
+	TotalPrice   int64  `json:"total_price"`
+}
+
+type ResDetailGetCart struct {
+	Items      []ResDetailCartItem `json:"items"`
+	TotalPrice int64               `json:"total_price"`
+}
+
+// the cart is split into one order per seller
+type ResDetailCheckout struct {
+	Orders     []ResDetailCheckoutOrder `json:"orders"`
+	TotalPrice int64                    `json:"total_price"`
+	Ordered_at string                   `json:"ordered_at"`
+}
+
+type ResDetailCheckoutOrder struct {
+	OrderId    uint                `json:"order_id"`
+	SellerId   *uint               `json:"seller_id,omitempty"`
+	Items      []ResDetailNewOrder `json:"items"`
+	TotalPrice int64               `json:"total_price"`
+}
@@ -1,22 +1,44 @@
 package dto
 
+import "final_project-ftgo-h8/quantity"
+
 type ReqBodyNewOrder struct {
 	ProductId uint `json:"product_id"`
-	Quantity  int  `json:"quantity"`
+	Quantity  quantity.Quantity `json:"quantity"`
 }
 
 type ResDetailNewOrder struct {
 	ProductName string  `json:"product_name"`
-	Quantity    int     `json:"quantity"`
+	Quantity    quantity.Quantity `json:"quantity"`
+	Unit        string  `json:"unit"`
 	TotalPrice  int64 `json:"total_price"`
 	Ordered_at  string  `json:"ordered_at"`
 }
 
 type ResDetailGetOrder struct {
+	OrderId            uint    `json:"order_id"`
+	Status             string  `json:"status"`
+	ProductId          uint    `json:"product_id"`
 	ProductName        string  `json:"product_name"`
 	ProductPrice       int64 `json:"product_price"`
 	ProductDescription string  `json:"product_description"`
-	Quantity           int     `json:"quantity"`
+	Quantity           quantity.Quantity `json:"quantity"`
+	Unit               string  `json:"unit"`
 	TotalPrice         int64 `json:"total_price"`
 	Ordered_at         string  `json:"ordered_at"`
 }
+
+type ReqBodyUpdateOrderStatus struct {
+	Status string `json:"status"`
+}
+
+type ResDetailOrderStatus struct {
+	OrderId    uint   `json:"order_id"`
+	Status     string `json:"status"`
+	TotalPrice int64  `json:"total_price"`
+}
+
+type ResDetailOrderStatusLog struct {
+	Status     string `json:"status"`
+	Changed_at string `json:"changed_at"`
+}
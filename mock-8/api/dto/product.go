@@ -1,15 +1,118 @@
 package dto
 
+import "final_project-ftgo-h8/quantity"
+
 type ReqBodyCreateProduct struct {
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       int64 `json:"price"`
-	Stock       int     `json:"stock"`
+	Name           string            `json:"name"`
+	Description    string            `json:"description"`
+	Price          int64             `json:"price"`
+	Unit           string            `json:"unit"`
+	OrderIncrement quantity.Quantity `json:"order_increment"`
+	// stock below it alerts the seller or admins, 0 turns alerts off
+	LowStockThreshold quantity.Quantity `json:"low_stock_threshold"`
+	CategoryId        string            `json:"category_id"`
+	Tags              []string          `json:"tags"`
+	// only used by admins, products of a seller always belong to the seller
+	SellerId string `json:"seller_id"`
 }
 
 type ReqBodyUpdateProduct struct {
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       int64 `json:"price"`
-	Stock       int     `json:"stock"`
-}
\ No newline at end of file
+	Name           string            `json:"name"`
+	Description    string            `json:"description"`
+	Price          int64             `json:"price"`
+	Unit           string            `json:"unit"`
+	OrderIncrement quantity.Quantity `json:"order_increment"`
+	// stock below it alerts the seller or admins, 0 turns alerts off
+	LowStockThreshold quantity.Quantity `json:"low_stock_threshold"`
+	CategoryId        string            `json:"category_id"`
+	Tags              []string          `json:"tags"`
+	// only used by admins, products of a seller always belong to the seller
+	SellerId string `json:"seller_id"`
+}
+
+type ReqQueryGetAllProducts struct {
+	Limit    int32
+	Offset   int32
+	MinPrice int64
+	MaxPrice int64
+	InStock  bool
+	Name     string
+	Sort     string
+	Category string
+	Tag      string
+	SellerId string
+}
+
+type ReqBodyCategory struct {
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+	ParentId string `json:"parent_id"`
+}
+
+// category with its sub categories
+type ResDetailCategory struct {
+	Id       string               `json:"id"`
+	Name     string               `json:"name"`
+	Slug     string               `json:"slug"`
+	ParentId string               `json:"parent_id,omitempty"`
+	Children []*ResDetailCategory `json:"children"`
+}
+
+type ResDetailProduct struct {
+	Id                string            `json:"id"`
+	Name              string            `json:"name"`
+	Description       string            `json:"description"`
+	Price             int64             `json:"price"`
+	Stock             quantity.Quantity `json:"stock"`
+	Unit              string            `json:"unit"`
+	OrderIncrement    quantity.Quantity `json:"order_increment"`
+	LowStockThreshold quantity.Quantity `json:"low_stock_threshold"`
+	CategoryId        string            `json:"category_id,omitempty"`
+	Tags              []string          `json:"tags"`
+	ImageUrls         []string          `json:"image_urls"`
+	ThumbnailUrls     []string          `json:"thumbnail_urls"`
+	// catch date of the freshest lot in stock, YYYY-MM-DD
+	FreshestCatchDate string  `json:"freshest_catch_date,omitempty"`
+	SellerId          string  `json:"seller_id,omitempty"`
+	RatingAverage     float64 `json:"rating_average"`
+	ReviewCount       int64   `json:"review_count"`
+}
+
+type ReqBodyAddStockLot struct {
+	LotNumber  string            `json:"lot_number"`
+	CatchDate  string            `json:"catch_date"`
+	OriginPort string            `json:"origin_port"`
+	Supplier   string            `json:"supplier"`
+	BestBefore string            `json:"best_before"`
+	Quantity   quantity.Quantity `json:"quantity"`
+}
+
+type ResDetailStockLot struct {
+	Id         string            `json:"id"`
+	ProductId  string            `json:"product_id"`
+	LotNumber  string            `json:"lot_number"`
+	CatchDate  string            `json:"catch_date"`
+	OriginPort string            `json:"origin_port"`
+	Supplier   string            `json:"supplier"`
+	BestBefore string            `json:"best_before"`
+	Quantity   quantity.Quantity `json:"quantity"`
+	Remaining  quantity.Quantity `json:"remaining"`
+	Status     string            `json:"status"`
+}
+
+type ReqQuerySearchProducts struct {
+	Q      string
+	Limit  int32
+	Offset int32
+}
+
+type ResDetailSearchResult struct {
+	Product ResDetailProduct `json:"product"`
+	Rank    float64          `json:"rank"`
+	Snippet string           `json:"snippet"`
+}
+
+type ResDetailRestockSubscription struct {
+	ProductId string `json:"product_id"`
+	CreatedAt string `json:"created_at,omitempty"`
+}
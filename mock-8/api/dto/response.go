@@ -12,6 +12,13 @@ type Response struct {
 	Detail  interface{}
 }
 
+type ResMetaPage struct {
+	Total      int64  `json:"total"`
+	Limit      int32  `json:"limit"`
+	Offset     int32  `json:"offset"`
+	NextOffset *int32 `json:"next_offset"`
+}
+
 func WriteResponse(ctx echo.Context, code int, message string) error {
 	return ctx.JSON(code, Response{
 		Message: message,
@@ -35,6 +42,12 @@ func ErrorResponse(ctx echo.Context, err error) error {
 			return echo.NewHTTPError(404, echo.Map{"message": "not found", "detail": st.Message()})
 		case codes.InvalidArgument:
 			return echo.NewHTTPError(400, echo.Map{"message": "invalid argument", "detail": st.Message()})
+		case codes.Unauthenticated:
+			return echo.NewHTTPError(401, echo.Map{"message": "unauthorized", "detail": st.Message()})
+		case codes.PermissionDenied:
+			return echo.NewHTTPError(403, echo.Map{"message": "forbidden", "detail": st.Message()})
+		case codes.AlreadyExists, codes.FailedPrecondition:
+			return echo.NewHTTPError(409, echo.Map{"message": "conflict", "detail": st.Message()})
 		default:
 			return echo.NewHTTPError(500, echo.Map{"message": "internal server error", "detail": st.Message()})
 		}
@@ -0,0 +1,26 @@
+package dto
+
+type ReqBodyNewReview struct {
+	ProductId uint   `json:"product_id"`
+	Rating    int32  `json:"rating"`
+	Body      string `json:"body"`
+}
+
+type ReqBodyModerateReview struct {
+	Hidden bool `json:"hidden"`
+}
+
+type ReqQueryGetReviews struct {
+	Limit  int32
+	Offset int32
+}
+
+type ResDetailReview struct {
+	Id        string `json:"id"`
+	ProductId string `json:"product_id"`
+	UserId    string `json:"user_id"`
+	Rating    int32  `json:"rating"`
+	Body      string `json:"body"`
+	Hidden    bool   `json:"hidden,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
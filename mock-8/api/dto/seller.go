@@ -0,0 +1,31 @@
+package dto
+
+import "final_project-ftgo-h8/quantity"
+
+type ReqBodySellerProfile struct {
+	StoreName         string `json:"store_name"`
+	Location          string `json:"location"`
+	BankName          string `json:"bank_name"`
+	BankAccountNumber string `json:"bank_account_number"`
+	BankAccountName   string `json:"bank_account_name"`
+}
+
+type ResDetailSellerOrderItem struct {
+	ProductId   uint              `json:"product_id"`
+	ProductName string            `json:"product_name"`
+	Quantity    quantity.Quantity `json:"quantity"`
+	Unit        string            `json:"unit"`
+	TotalPrice  int64             `json:"total_price"`
+}
+
+// seller order with where to ship it
+type ResDetailSellerOrder struct {
+	OrderId      uint                       `json:"order_id"`
+	Status       string                     `json:"status"`
+	BuyerName    string                     `json:"buyer_name"`
+	BuyerAddress string                     `json:"buyer_address"`
+	BuyerPhone   string                     `json:"buyer_phone"`
+	Items        []ResDetailSellerOrderItem `json:"items"`
+	TotalPrice   int64                      `json:"total_price"`
+	Ordered_at   string                     `json:"ordered_at"`
+}
@@ -7,6 +7,8 @@ type ReqBodyRegister struct {
 	Address  string  `json:"address"`
 	Phone    string  `json:"phone"`
 	Role     string  `json:"role"`
+	// required when registering as a seller
+	Seller *ReqBodySellerProfile `json:"seller,omitempty"`
 }
 
 type ReqBodyLogin struct {
@@ -14,12 +16,34 @@ type ReqBodyLogin struct {
 	Password string `json:"password"`
 }
 
-type UserEmailVerification struct {
-	Email            string `json:"email"`
-	UserId           uint   `json:"user_id"`
-	VerificationCode string `json:"verification_code"`
-}
-
 type TopUpReqBody struct {
 	Amount	int64 `json:"amount"`
-}
\ No newline at end of file
+}
+
+type ReqBodyMidtransNotification struct {
+	OrderId           string `json:"order_id"`
+	StatusCode        string `json:"status_code"`
+	GrossAmount       string `json:"gross_amount"`
+	SignatureKey      string `json:"signature_key"`
+	TransactionStatus string `json:"transaction_status"`
+	FraudStatus       string `json:"fraud_status"`
+}
+
+type ReqBodyRefreshToken struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type ResDetailToken struct {
+	Jwt          string `json:"jwt"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type ReqBodyForgotPassword struct {
+	Email string `json:"email"`
+}
+
+type ReqBodyResetPassword struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
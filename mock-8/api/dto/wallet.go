@@ -0,0 +1,25 @@
+package dto
+
+import "time"
+
+type ReqQueryGetWalletTransactions struct {
+	Limit  int
+	Offset int
+	From   time.Time
+	To     time.Time
+}
+
+type ReqBodyWalletAdjustment struct {
+	Amount int64  `json:"amount"`
+	Note   string `json:"note"`
+}
+
+type ResDetailWalletTransaction struct {
+	Type         string `json:"type"`
+	Amount       int64  `json:"amount"`
+	BalanceAfter int64  `json:"balance_after"`
+	OrderId      *uint  `json:"order_id,omitempty"`
+	TopUpId      string `json:"top_up_id,omitempty"`
+	Note         string `json:"note,omitempty"`
+	Created_at   string `json:"created_at"`
+}
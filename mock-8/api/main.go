@@ -1,17 +1,59 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"final_project-ftgo-h8/api/router"
-	"final_project-ftgo-h8/helper"
+	"final_project-ftgo-h8/config"
+	"final_project-ftgo-h8/lifecycle"
+	"final_project-ftgo-h8/metrics"
+	"final_project-ftgo-h8/tracing"
+	"log"
+	"net/http"
 )
 
 func main(){
-	// load env
-	helper.LoadEnv()
+	// load config
+	cfg := config.MustLoad(config.ServiceAPI)
+
+	// components are stopped in reverse order on shutdown
+	lc := lifecycle.New(cfg.ShutdownTimeout)
+
+	// init tracing, added first so spans are flushed after everything else stopped
+	shutdownTracing, err := tracing.Setup(config.ServiceAPI, cfg.Tracing)
+	if err != nil {
+		log.Fatal(err)
+	}
+	lc.Add(lifecycle.Component{Name: "tracing", Stop: shutdownTracing})
 
 	// init app rest api
-	app := router.NewEchoInstance()
+	app := router.NewEchoInstance(cfg, lc)
+
+	// serve metrics on the internal address
+	metricsServer := &http.Server{Addr: cfg.API.MetricsAddr, Handler: metrics.Handler()}
+	lc.Add(lifecycle.Component{
+		Name: "metrics server",
+		Start: func(ctx context.Context) error {
+			if err := metricsServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+		Stop: metricsServer.Shutdown,
+	})
 
-	// run app
-	app.Logger.Fatal(app.Start(":8080"))
+	// run app, in-flight requests are drained on shutdown
+	lc.Add(lifecycle.Component{
+		Name: "http server",
+		Start: func(ctx context.Context) error {
+			if err := app.Start(cfg.API.Addr); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+		Stop: app.Shutdown,
+	})
+	if err := lc.Run(); err != nil {
+		log.Fatal(err)
+	}
 }
\ No newline at end of file
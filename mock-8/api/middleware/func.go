@@ -2,9 +2,10 @@ package middleware
 
 import (
 	"final_project-ftgo-h8/api/dto"
+	"final_project-ftgo-h8/api/model"
 	"final_project-ftgo-h8/helper"
-	"os"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 )
 
@@ -17,14 +18,16 @@ func (a *authenticationMiddleware) Authentication(next echo.HandlerFunc) echo.Ha
 		}
 
 		// init claim
-		secretsign := []byte(os.Getenv("SECRETSIGN"))
-		claims,err := helper.ParseJWT(tokenString, secretsign)
+		claims,err := helper.ParseJWT(tokenString, a.secretSign)
 		if err != nil {
 			return dto.WriteResponseWithDetail(c,401,"unauthorized user",err.Error())
 		}
 		
 		// type assertion
-		userId := claims["user_id"].(float64)
+		userId, ok := claims["user_id"].(float64)
+		if !ok {
+			return dto.WriteResponse(c, 401, "unauthorized user")
+		}
 
 		// init user from db
 		user,err := a.repository.FindUserById(uint(userId))
@@ -32,6 +35,11 @@ func (a *authenticationMiddleware) Authentication(next echo.HandlerFunc) echo.Ha
 			return dto.WriteResponseWithDetail(c,401,"undefined user",err.Error())
 		}
 
+		// sessions end when the password changes
+		if issuedBeforePasswordChange(claims, user) {
+			return dto.WriteResponse(c,401,"unauthorized user")
+		}
+
 		// set user to context
 		c.Set("user",user)
 		
@@ -48,14 +56,16 @@ func (a *authenticationMiddleware) AuthAdmin(next echo.HandlerFunc) echo.Handler
 		}
 
 		// init claim
-		secretsign := []byte(os.Getenv("SECRETSIGN"))
-		claims, err := helper.ParseJWT(tokenString, secretsign)
+		claims, err := helper.ParseJWT(tokenString, a.secretSign)
 		if err != nil {
 			return dto.WriteResponseWithDetail(c, 401, "unauthorized user", err.Error())
 		}
 
 		// type assertion
-		userId := claims["user_id"].(float64)
+		userId, ok := claims["user_id"].(float64)
+		if !ok {
+			return dto.WriteResponse(c, 401, "unauthorized user")
+		}
 
 		// init user from db
 		user, err := a.repository.FindUserById(uint(userId))
@@ -63,6 +73,11 @@ func (a *authenticationMiddleware) AuthAdmin(next echo.HandlerFunc) echo.Handler
 			return dto.WriteResponseWithDetail(c, 401, "undefined user", err.Error())
 		}
 
+		// sessions end when the password changes
+		if issuedBeforePasswordChange(claims, user) {
+			return dto.WriteResponse(c, 401, "unauthorized user")
+		}
+
 		// Check user role is "admin"
 		if user.Role != "Admin" {
 			return dto.WriteResponse(c, 403, "forbidden. user is not an admin.")
@@ -73,4 +88,40 @@ func (a *authenticationMiddleware) AuthAdmin(next echo.HandlerFunc) echo.Handler
 
 		return next(c)
 	}
-}
\ No newline at end of file
+}
+
+func (a *authenticationMiddleware) AuthSeller(next echo.HandlerFunc) echo.HandlerFunc {
+	return a.Authentication(func(c echo.Context) error {
+		// Check user role is "seller"
+		if c.Get("user").(model.User).Role != "Seller" {
+			return dto.WriteResponse(c, 403, "forbidden. user is not a seller.")
+		}
+
+		return next(c)
+	})
+}
+
+func (a *authenticationMiddleware) AuthAdminOrSeller(next echo.HandlerFunc) echo.HandlerFunc {
+	return a.Authentication(func(c echo.Context) error {
+		// Check user role is "admin" or "seller", product-service limits sellers to their own products
+		role := c.Get("user").(model.User).Role
+		if role != "Admin" && role != "Seller" {
+			return dto.WriteResponse(c, 403, "forbidden. user is not an admin or seller.")
+		}
+
+		return next(c)
+	})
+}
+
+func issuedBeforePasswordChange(claims jwt.MapClaims, user model.User) bool {
+	if user.PasswordChangedAt == nil {
+		return false
+	}
+
+	iat, err := claims.GetIssuedAt()
+	if err != nil || iat == nil {
+		return true
+	}
+
+	return iat.Unix() < user.PasswordChangedAt.Unix()
+}
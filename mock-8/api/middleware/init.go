@@ -9,12 +9,15 @@ import (
 type AuthenticationMiddleware interface {
 	Authentication(next echo.HandlerFunc) echo.HandlerFunc
 	AuthAdmin(next echo.HandlerFunc) echo.HandlerFunc
+	AuthSeller(next echo.HandlerFunc) echo.HandlerFunc
+	AuthAdminOrSeller(next echo.HandlerFunc) echo.HandlerFunc
 }
 
 type authenticationMiddleware struct {
 	repository repository.UserRepository
+	secretSign []byte
 }
 
-func NewAuthenticationMiddleware(r repository.UserRepository) AuthenticationMiddleware {
-	return &authenticationMiddleware{repository: r}
+func NewAuthenticationMiddleware(r repository.UserRepository, secretSign string) AuthenticationMiddleware {
+	return &authenticationMiddleware{repository: r, secretSign: []byte(secretSign)}
 }
\ No newline at end of file
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestBodyLimit(t *testing.T) {
+	handler := BodyLimit(10)(func(c echo.Context) error {
+		_, err := io.ReadAll(c.Request().Body)
+		return err
+	})
+
+	tests := []struct {
+		name          string
+		body          string
+		contentLength int64
+		wantStatus    int
+	}{
+		{"within limit", "0123456789", 10, http.StatusOK},
+		{"declared too large", "0123456789a", 11, http.StatusRequestEntityTooLarge},
+		// chunked bodies have no content length and are cut off while read
+		{"undeclared too large", "0123456789a", -1, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.ContentLength = tt.contentLength
+			c := echo.New().NewContext(req, httptest.NewRecorder())
+
+			status := http.StatusOK
+			err := handler(c)
+			var httpErr *echo.HTTPError
+			var maxBytesErr *http.MaxBytesError
+			switch {
+			case errors.As(err, &httpErr):
+				status = httpErr.Code
+			case errors.As(err, &maxBytesErr):
+				status = http.StatusRequestEntityTooLarge
+			case err != nil:
+				t.Fatal(err)
+			}
+			if status != tt.wantStatus {
+				t.Errorf("status = %d, want %d", status, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"errors"
+	"final_project-ftgo-h8/metrics"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Metrics records the count and latency of every request by route template and status.
+func Metrics(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+		err := next(c)
+
+		// the error handler writes the response after the middleware returns
+		status := c.Response().Status
+		if err != nil {
+			status = http.StatusInternalServerError
+			var httpErr *echo.HTTPError
+			if errors.As(err, &httpErr) {
+				status = httpErr.Code
+			}
+		}
+
+		route := c.Path()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.ObserveHTTPRequest(c.Request().Method, route, status, time.Since(start))
+
+		return err
+	}
+}
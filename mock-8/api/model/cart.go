@@ -0,0 +1,11 @@
+package model
+
+import "final_project-ftgo-h8/quantity"
+
+type CartItem struct {
+	Id        uint    `json:"id,omitempty"`
+	UserId    uint    `json:"user_id,omitempty"`
+	ProductId uint    `json:"product_id"`
+	Product   Product `json:"product"`
+	Quantity  quantity.Quantity `json:"quantity"`
+}
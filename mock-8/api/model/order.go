@@ -1,11 +1,54 @@
 package model
 
-import "time"
+import (
+	"final_project-ftgo-h8/quantity"
+	"time"
+)
+
+// order status
+const (
+	OrderStatusPending   = "Pending"
+	OrderStatusPaid      = "Paid"
+	OrderStatusPacked    = "Packed"
+	OrderStatusShipped   = "Shipped"
+	OrderStatusDelivered = "Delivered"
+	OrderStatusCancelled = "Cancelled"
+)
+
+// allowed next status for each order status
+var orderStatusTransitions = map[string][]string{
+	OrderStatusPending: {OrderStatusPaid, OrderStatusCancelled},
+	OrderStatusPaid:    {OrderStatusPacked, OrderStatusCancelled},
+	OrderStatusPacked:  {OrderStatusShipped, OrderStatusCancelled},
+	OrderStatusShipped: {OrderStatusDelivered},
+}
+
+func CanTransitionOrderStatus(from, to string) bool {
+	for _, v := range orderStatusTransitions[from] {
+		if v == to {
+			return true
+		}
+	}
+	return false
+}
 
 type Order struct {
+	Id            uint      `json:"id,omitempty"`
+	UserId        uint      `json:"user_id,omitempty"`
+	OrderDate     time.Time `json:"order_date"`
+	Status        string    `json:"status"`
+	TotalPrice    int64     `json:"total_price"`
+	ReservationId string    `json:"-"`
+	// orders are split per seller, nil for products sold by the marketplace
+	SellerId *uint `json:"seller_id,omitempty"`
+	Buyer    *User `gorm:"foreignKey:UserId" json:"-"`
+}
+
+type OrderStatusLog struct {
 	Id        uint      `json:"id,omitempty"`
-	UserId    uint      `json:"user_id,omitempty"`
-	OrderDate time.Time `json:"order_date"`
+	OrderId   uint      `json:"order_id"`
+	Status    string    `json:"status"`
+	ChangedAt time.Time `json:"changed_at"`
 }
 
 type OrderDetail struct {
@@ -14,6 +57,6 @@ type OrderDetail struct {
 	Order		Order	  `json:"order"`
 	ProductId   uint 	  `json:"product_id"`
 	Product		Product	  `json:"product"`
-	Quantity    int   	  `json:"quantity"`
+	Quantity    quantity.Quantity `json:"quantity"`
 	TotalPrice  int64   `json:"total_price"`
 }
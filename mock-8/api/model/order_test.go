@@ -0,0 +1,39 @@
+package model
+
+import "testing"
+
+func TestCanTransitionOrderStatus(t *testing.T) {
+	statuses := []string{
+		OrderStatusPending,
+		OrderStatusPaid,
+		OrderStatusPacked,
+		OrderStatusShipped,
+		OrderStatusDelivered,
+		OrderStatusCancelled,
+	}
+
+	// every allowed transition, any other pair is rejected
+	allowed := map[[2]string]bool{
+		{OrderStatusPending, OrderStatusPaid}:      true,
+		{OrderStatusPending, OrderStatusCancelled}: true,
+		{OrderStatusPaid, OrderStatusPacked}:       true,
+		{OrderStatusPaid, OrderStatusCancelled}:    true,
+		{OrderStatusPacked, OrderStatusShipped}:    true,
+		{OrderStatusPacked, OrderStatusCancelled}:  true,
+		{OrderStatusShipped, OrderStatusDelivered}: true,
+	}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			want := allowed[[2]string{from, to}]
+			if got := CanTransitionOrderStatus(from, to); got != want {
+				t.Errorf("CanTransitionOrderStatus(%s, %s) = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+
+	// unknown statuses never move
+	if CanTransitionOrderStatus("Refunded", OrderStatusPaid) || CanTransitionOrderStatus(OrderStatusPaid, "Refunded") {
+		t.Error("unknown status accepted")
+	}
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// OutboxMessage is an event saved in the same transaction as the change it describes,
+// the outbox relay publishes it afterwards.
+type OutboxMessage struct {
+	Id        uint   `json:"id"`
+	Queue     string `json:"queue"`
+	EventType string `json:"event_type"`
+	Payload   []byte `json:"payload"`
+	Attempts  int    `json:"attempts"`
+	LastError string `json:"last_error,omitempty"`
+	// w3c traceparent of the request that wrote the message, the relay publishes it in that trace
+	TraceParent string     `json:"trace_parent,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	SentAt      *time.Time `json:"sent_at,omitempty"`
+}
+
+func (OutboxMessage) TableName() string {
+	return "outbox"
+}
+
+type OutboxStats struct {
+	Pending         int64      `json:"pending"`
+	OldestPendingAt *time.Time `json:"oldest_pending_at,omitempty"`
+	LagSeconds      float64    `json:"lag_seconds"`
+	Published       uint64     `json:"published"`
+	Failed          uint64     `json:"failed"`
+	LastRelayedAt   *time.Time `json:"last_relayed_at,omitempty"`
+}
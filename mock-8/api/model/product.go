@@ -1,9 +1,15 @@
 package model
 
+import "final_project-ftgo-h8/quantity"
+
 type Product struct {
 	ID          uint    `json:"id"`
 	Name        string  `json:"name"`
 	Description string  `json:"description"`
 	Price       int64 `json:"price"`
-	Stock       int     `json:"stock"`
+	Stock       quantity.Quantity `json:"stock"`
+	Unit        string  `json:"unit"`
+	OrderIncrement quantity.Quantity `json:"order_increment"`
+	CategoryId  *uint   `json:"category_id,omitempty"`
+	SellerId    *uint   `json:"seller_id,omitempty"`
 }
\ No newline at end of file
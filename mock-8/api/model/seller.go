@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// SellerProfile is the store of a user with the Seller role, products of the seller
+// are paid out to the bank account.
+type SellerProfile struct {
+	UserId            uint      `gorm:"primaryKey" json:"user_id"`
+	StoreName         string    `json:"store_name"`
+	Location          string    `json:"location"`
+	BankName          string    `json:"bank_name"`
+	BankAccountNumber string    `json:"bank_account_number"`
+	BankAccountName   string    `json:"bank_account_name"`
+	CreatedAt         time.Time `json:"created_at"`
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+type TopUp struct {
+	OrderId     string    `bson:"orderId" json:"order_id"`
+	UserId      uint      `bson:"userId" json:"user_id"`
+	Amount      int64     `bson:"amount" json:"amount"`
+	SnapToken   string    `bson:"snapToken" json:"snap_token"`
+	RedirectURL string    `bson:"redirectURL" json:"redirect_url"`
+	Status      string    `bson:"status" json:"status"`
+	CreatedAt   time.Time `bson:"createdAt" json:"created_at"`
+}
@@ -13,10 +13,30 @@ type User struct {
 	Amount		 int64 `json:"amount"`
 	Role 		 string `json:"role"`
 	RegisteredAt time.Time	`json:"registered_at"`
+	PasswordChangedAt *time.Time `json:"-"`
 }
 
 type UserVerification struct {
 	Id           		uint	`json:"id"`
 	UserID				uint 	`json:"user_id"`
 	VerificationCode 	string `json:"verification_code"`
-}
\ No newline at end of file
+}
+
+type RefreshToken struct {
+	Id        uint       `json:"id"`
+	UserId    uint       `json:"user_id"`
+	FamilyId  string     `json:"family_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type PasswordReset struct {
+	Id        uint       `json:"id"`
+	UserId    uint       `json:"user_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
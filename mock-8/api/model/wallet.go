@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// wallet transaction type
+const (
+	WalletTransactionTopUp      = "Top-Up"
+	WalletTransactionPurchase   = "Purchase"
+	WalletTransactionRefund     = "Refund"
+	WalletTransactionAdjustment = "Adjustment"
+)
+
+type WalletTransaction struct {
+	Id           uint      `json:"id,omitempty"`
+	UserId       uint      `json:"user_id,omitempty"`
+	Type         string    `json:"type"`
+	Amount       int64     `json:"amount"`
+	BalanceAfter int64     `json:"balance_after"`
+	OrderId      *uint     `json:"order_id,omitempty"`
+	TopUpId      string    `json:"top_up_id,omitempty"`
+	Note         string    `json:"note,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
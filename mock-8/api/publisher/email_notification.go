@@ -2,21 +2,41 @@ package publisher
 
 import (
 	"context"
+	"final_project-ftgo-h8/helper"
+	"final_project-ftgo-h8/metrics"
+	"final_project-ftgo-h8/tracing"
 
 	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func (pub *emailNotification) PublishMessage(ctx context.Context,queueName string, message []byte) error{
+	// the consumer continues the trace from the message headers
+	ctx, span := tracing.Tracer().Start(ctx, queueName+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(semconv.MessagingSystem("rabbitmq"), semconv.MessagingDestinationName(queueName)),
+	)
+	defer span.End()
+
 	err := pub.channel.PublishWithContext(ctx,
 	"",     // exchange
 	queueName, // routing key
 	false,  // mandatory
 	false,  // immediate
 	amqp.Publishing {
-		ContentType: "text/plain",
-		Body:        message,
+		ContentType:  "text/plain",
+		// names the message in consumer logs, kept on retries
+		MessageId:    helper.GenerateRandomString(16),
+		Headers:      tracing.InjectAMQP(ctx, nil),
+		DeliveryMode: amqp.Persistent,
+		Body:         message,
 	})
+	metrics.ObservePublish(queueName, err)
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return err
 	}
 	
@@ -0,0 +1,95 @@
+package publisher
+
+import (
+	"context"
+	"final_project-ftgo-h8/api/model"
+	"final_project-ftgo-h8/api/repository"
+	"final_project-ftgo-h8/tracing"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// number of outbox messages published per relay run
+const outboxBatchSize = 100
+
+// OutboxRelay publishes the events saved in the outbox table and marks them sent.
+type OutboxRelay struct {
+	repository repository.OutboxRepository
+	publisher  Publisher
+
+	published atomic.Uint64
+	failed    atomic.Uint64
+
+	mu            sync.Mutex
+	lastRelayedAt *time.Time
+}
+
+func NewOutboxRelay(r repository.OutboxRepository, p Publisher) *OutboxRelay {
+	return &OutboxRelay{
+		repository: r,
+		publisher:  p,
+	}
+}
+
+// Run relays pending outbox messages every interval until ctx is done.
+func (o *OutboxRelay) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			o.relay(ctx)
+		}
+	}
+}
+
+// relay publishes full batches until the outbox is drained or a publish fails
+func (o *OutboxRelay) relay(ctx context.Context) {
+	for {
+		var publishErr error
+		sent, err := o.repository.RelayOutboxMessages(outboxBatchSize, func(msg model.OutboxMessage) error {
+			publishErr = o.publisher.PublishMessage(tracing.WithTraceParent(ctx, msg.TraceParent), msg.Queue, msg.Payload)
+			return publishErr
+		})
+		o.published.Add(uint64(sent))
+
+		now := time.Now()
+		o.mu.Lock()
+		o.lastRelayedAt = &now
+		o.mu.Unlock()
+
+		if publishErr != nil {
+			o.failed.Add(1)
+			log.Printf("failed to publish outbox message: %v", publishErr)
+			return
+		}
+		if err != nil {
+			log.Printf("failed to relay outbox: %v", err)
+			return
+		}
+		if sent < outboxBatchSize {
+			return
+		}
+	}
+}
+
+// Stats returns the outbox lag together with the relay counters.
+func (o *OutboxRelay) Stats() (model.OutboxStats, error) {
+	stats, err := o.repository.FindOutboxStats()
+	if err != nil {
+		return model.OutboxStats{}, err
+	}
+
+	stats.Published = o.published.Load()
+	stats.Failed = o.failed.Load()
+	o.mu.Lock()
+	stats.LastRelayedAt = o.lastRelayedAt
+	o.mu.Unlock()
+
+	return stats, nil
+}
@@ -0,0 +1,49 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"final_project-ftgo-h8/event"
+	"final_project-ftgo-h8/helper"
+	"final_project-ftgo-h8/pb"
+	"fmt"
+	"log"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// productService delivers the outbox messages of event.ProductServiceQueue over gRPC.
+type productService struct {
+	client pb.ProductServiceClient
+}
+
+func NewProductServicePublisher(c pb.ProductServiceClient) Publisher {
+	return &productService{client: c}
+}
+
+func (pub *productService) PublishMessage(ctx context.Context, queueName string, message []byte) error {
+	envelope, err := event.Unmarshal(message)
+	if err != nil {
+		return err
+	}
+
+	switch envelope.Type {
+	case event.TypeStockCancel:
+		var payload event.StockCancel
+		err = json.Unmarshal(envelope.Payload, &payload)
+		if err != nil {
+			return err
+		}
+
+		// cancelling twice is a no-op, so redelivery after a failed relay is safe
+		_, err = pub.client.CancelReservation(helper.AppendGatewayMetadata(ctx), &pb.CancelReservationRequest{ReservationId: payload.ReservationId})
+		if status.Code(err) == codes.NotFound {
+			log.Printf("stock reservation %s not found, nothing to return", payload.ReservationId)
+			return nil
+		}
+		return err
+	}
+
+	return fmt.Errorf("unknown %s event type %q", queueName, envelope.Type)
+}
@@ -0,0 +1,80 @@
+package publisher
+
+import (
+	"context"
+	"final_project-ftgo-h8/event"
+	"final_project-ftgo-h8/helper"
+	"final_project-ftgo-h8/pb"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// stubProductService records CancelReservation calls, other methods are not used
+type stubProductService struct {
+	pb.ProductServiceClient
+	err       error
+	role      string
+	cancelled []string
+}
+
+func (s *stubProductService) CancelReservation(ctx context.Context, in *pb.CancelReservationRequest, opts ...grpc.CallOption) (*pb.Reservation, error) {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if roles := md.Get(helper.MetadataUserRole); len(roles) > 0 {
+		s.role = roles[0]
+	}
+	s.cancelled = append(s.cancelled, in.GetReservationId())
+	return &pb.Reservation{Id: in.GetReservationId()}, s.err
+}
+
+func TestProductServicePublisherCancelsStock(t *testing.T) {
+	msg, err := event.Marshal(event.TypeStockCancel, event.StockCancel{ReservationId: "order-1-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		err     error
+		wantErr bool
+	}{
+		{"cancelled", nil, false},
+		{"reservation gone", status.Error(codes.NotFound, "not found"), false},
+		{"product-service down", status.Error(codes.Unavailable, "unavailable"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &stubProductService{err: tt.err}
+			err := NewProductServicePublisher(client).PublishMessage(context.Background(), event.ProductServiceQueue, msg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(client.cancelled) != 1 || client.cancelled[0] != "order-1-1" {
+				t.Errorf("cancelled = %v, want [order-1-1]", client.cancelled)
+			}
+			if client.role != "Admin" {
+				t.Errorf("role = %q, want Admin", client.role)
+			}
+		})
+	}
+}
+
+func TestProductServicePublisherRejectsUnknownEvent(t *testing.T) {
+	msg, err := event.Marshal("stock.unknown", struct{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &stubProductService{}
+	err = NewProductServicePublisher(client).PublishMessage(context.Background(), event.ProductServiceQueue, msg)
+	if err == nil {
+		t.Fatal("expected an error for an unknown event type")
+	}
+	if len(client.cancelled) != 0 {
+		t.Errorf("cancelled = %v, want none", client.cancelled)
+	}
+}
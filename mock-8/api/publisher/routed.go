@@ -0,0 +1,20 @@
+package publisher
+
+import "context"
+
+// routedPublisher sends the messages of a queue in routes to its publisher and every other message to fallback.
+type routedPublisher struct {
+	routes   map[string]Publisher
+	fallback Publisher
+}
+
+func NewRoutedPublisher(fallback Publisher, routes map[string]Publisher) Publisher {
+	return &routedPublisher{routes: routes, fallback: fallback}
+}
+
+func (pub *routedPublisher) PublishMessage(ctx context.Context, queueName string, message []byte) error {
+	if route, ok := pub.routes[queueName]; ok {
+		return route.PublishMessage(ctx, queueName, message)
+	}
+	return pub.fallback.PublishMessage(ctx, queueName, message)
+}
@@ -0,0 +1,304 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"final_project-ftgo-h8/api/model"
+	"final_project-ftgo-h8/event"
+	"final_project-ftgo-h8/pb"
+	"final_project-ftgo-h8/quantity"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrCartEmpty = errors.New("cart is empty")
+
+func (r *cartRepository) FindCartItems(userId uint) ([]model.CartItem, error) {
+	// model
+	cartItems := []model.CartItem{}
+
+	// find
+	result := r.gormDb.Preload("Product").Where("user_id = ?", userId).Order("id").Find(&cartItems)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return cartItems, nil
+}
+
+func (r *cartRepository) AddCartItem(userId uint, productId uint, q quantity.Quantity) (model.CartItem, error) {
+	// check product
+	var product model.Product
+	result := r.gormDb.First(&product, productId)
+	if result.Error != nil {
+		return model.CartItem{}, result.Error
+	}
+
+	// add quantity to existing line or create a new one
+	cartItem := model.CartItem{}
+	result = r.gormDb.Where("user_id = ? and product_id = ?", userId, productId).First(&cartItem)
+	if result.Error != nil {
+		if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return model.CartItem{}, result.Error
+		}
+		cartItem = model.CartItem{
+			UserId:    userId,
+			ProductId: productId,
+		}
+	}
+	cartItem.Quantity += q
+	err := checkOrderIncrement(product, cartItem.Quantity)
+	if err != nil {
+		return model.CartItem{}, err
+	}
+
+	result = r.gormDb.Omit("Product").Save(&cartItem)
+	if result.Error != nil {
+		return model.CartItem{}, result.Error
+	}
+	cartItem.Product = product
+
+	return cartItem, nil
+}
+
+func (r *cartRepository) UpdateCartItem(userId uint, productId uint, q quantity.Quantity) (model.CartItem, error) {
+	// find line
+	cartItem := model.CartItem{}
+	result := r.gormDb.Preload("Product").Where("user_id = ? and product_id = ?", userId, productId).First(&cartItem)
+	if result.Error != nil {
+		return model.CartItem{}, result.Error
+	}
+
+	// update
+	err := checkOrderIncrement(cartItem.Product, q)
+	if err != nil {
+		return model.CartItem{}, err
+	}
+	cartItem.Quantity = q
+	result = r.gormDb.Omit("Product").Save(&cartItem)
+	if result.Error != nil {
+		return model.CartItem{}, result.Error
+	}
+
+	return cartItem, nil
+}
+
+func (r *cartRepository) DeleteCartItem(userId uint, productId uint) error {
+	// delete
+	result := r.gormDb.Where("user_id = ? and product_id = ?", userId, productId).Delete(&model.CartItem{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *cartRepository) Checkout(ctx context.Context, userId uint) ([]model.Order, []model.OrderDetail, error) {
+	// init cart
+	var cartItems []model.CartItem
+	result := r.gormDb.WithContext(ctx).Preload("Product").Where("user_id = ?", userId).Order("product_id").Find(&cartItems)
+	if result.Error != nil {
+		return nil, nil, result.Error
+	}
+	if len(cartItems) == 0 {
+		return nil, nil, ErrCartEmpty
+	}
+
+	// reserve stock of every seller order in product-service, all or nothing
+	sellerCarts := splitCartBySeller(cartItems)
+	baseReservationId := newReservationId(userId)
+	reservationIds := make([]string, 0, len(sellerCarts))
+	for i, cart := range sellerCarts {
+		cart.reservationId = fmt.Sprintf("%s-%d", baseReservationId, i+1)
+		stockItems := make([]*pb.StockItem, 0, len(cart.items))
+		for _, v := range cart.items {
+			stockItems = append(stockItems, toPbStockItem(v.ProductId, v.Quantity))
+		}
+
+		reservation, err := r.productService.ReserveStock(ctx, &pb.ReserveStockRequest{
+			ReservationId: cart.reservationId,
+			Items:         stockItems,
+		})
+		if err != nil {
+			for _, reservationId := range reservationIds {
+				releaseStock(ctx, r.productService, reservationId)
+			}
+			return nil, nil, err
+		}
+		reservationIds = append(reservationIds, cart.reservationId)
+
+		// reserved name and price by product id
+		cart.products = map[string]*pb.StockItem{}
+		for _, v := range reservation.GetItems() {
+			cart.products[v.GetProductId()] = v
+		}
+	}
+
+	// init and start gorm transaction
+	tx := r.gormDb.WithContext(ctx).Begin()
+
+	// lock user so concurrent checkouts can not spend the same amount
+	var user model.User
+	result = tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userId)
+	if result.Error != nil {
+		rollbackOrder(tx, r.productService, reservationIds...)
+		return nil, nil, result.Error
+	}
+
+	orderDate := time.Now()
+	orders := make([]model.Order, 0, len(sellerCarts))
+	orderDetails := make([]model.OrderDetail, 0, len(cartItems))
+	cartItemIds := make([]uint, 0, len(cartItems))
+	for _, cart := range sellerCarts {
+		order, details, err := insertSellerOrder(tx, &user, cart, orderDate)
+		if err != nil {
+			rollbackOrder(tx, r.productService, reservationIds...)
+			return nil, nil, err
+		}
+		orders = append(orders, order)
+		orderDetails = append(orderDetails, details...)
+		for _, v := range cart.items {
+			cartItemIds = append(cartItemIds, v.Id)
+		}
+	}
+
+	result = tx.Save(&user)
+	if result.Error != nil {
+		rollbackOrder(tx, r.productService, reservationIds...)
+		return nil, nil, result.Error
+	}
+
+	// empty the checked out lines
+	result = tx.Where("user_id = ? and id in ?", userId, cartItemIds).Delete(&model.CartItem{})
+	if result.Error != nil {
+		rollbackOrder(tx, r.productService, reservationIds...)
+		return nil, nil, result.Error
+	}
+
+	err := commitOrder(tx, r.productService, reservationIds...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return orders, orderDetails, nil
+}
+
+// sellerCart is the part of a cart sold by one seller, it is checked out as its own order
+type sellerCart struct {
+	sellerId      *uint
+	items         []model.CartItem
+	reservationId string
+	// reserved name and price by product id
+	products map[string]*pb.StockItem
+}
+
+// splitCartBySeller groups cart lines by the seller of their product, in order of first appearance
+func splitCartBySeller(cartItems []model.CartItem) []*sellerCart {
+	var sellerCarts []*sellerCart
+	bySeller := map[uint]*sellerCart{}
+	for _, v := range cartItems {
+		// marketplace products have no seller and share key 0
+		var key uint
+		if v.Product.SellerId != nil {
+			key = *v.Product.SellerId
+		}
+
+		cart, ok := bySeller[key]
+		if !ok {
+			cart = &sellerCart{sellerId: v.Product.SellerId}
+			bySeller[key] = cart
+			sellerCarts = append(sellerCarts, cart)
+		}
+		cart.items = append(cart.items, v)
+	}
+	return sellerCarts
+}
+
+// insertSellerOrder creates the order of one seller and pays it from the user amount,
+// the caller saves the user.
+func insertSellerOrder(tx *gorm.DB, user *model.User, cart *sellerCart, orderDate time.Time) (model.Order, []model.OrderDetail, error) {
+	// create order, pending until it is paid
+	order := model.Order{
+		UserId:        user.Id,
+		OrderDate:     orderDate,
+		Status:        model.OrderStatusPending,
+		ReservationId: cart.reservationId,
+		SellerId:      cart.sellerId,
+	}
+
+	result := tx.Create(&order)
+	if result.Error != nil {
+		return model.Order{}, nil, result.Error
+	}
+
+	err := insertOrderStatusLog(tx, order.Id, order.Status, order.OrderDate)
+	if err != nil {
+		return model.Order{}, nil, err
+	}
+
+	var totalPrice int64
+	orderDetails := make([]model.OrderDetail, 0, len(cart.items))
+	for _, v := range cart.items {
+		stockItem := cart.products[strconv.FormatUint(uint64(v.ProductId), 10)]
+		product := model.Product{
+			ID:       v.ProductId,
+			Name:     stockItem.GetName(),
+			Price:    stockItem.GetPrice(),
+			Unit:     stockItem.GetUnit(),
+			SellerId: cart.sellerId,
+		}
+
+		// create order detail
+		lineTotal, err := v.Quantity.Total(product.Price)
+		if err != nil {
+			return model.Order{}, nil, err
+		}
+		orderDetail := model.OrderDetail{
+			OrderId:    order.Id,
+			Order:      order,
+			ProductId:  product.ID,
+			Product:    product,
+			TotalPrice: lineTotal,
+			Quantity:   v.Quantity,
+		}
+
+		result = tx.Omit("Order", "Product").Create(&orderDetail)
+		if result.Error != nil {
+			return model.Order{}, nil, result.Error
+		}
+
+		totalPrice += orderDetail.TotalPrice
+		orderDetails = append(orderDetails, orderDetail)
+	}
+
+	// update order total
+	order.TotalPrice = totalPrice
+	result = tx.Model(&order).Update("total_price", totalPrice)
+	if result.Error != nil {
+		return model.Order{}, nil, result.Error
+	}
+
+	// pay order from user amount
+	err = payOrder(tx, user, &order)
+	if err != nil {
+		return model.Order{}, nil, err
+	}
+	for i := range orderDetails {
+		orderDetails[i].Order = order
+	}
+
+	// send order confirmation
+	err = insertOutboxMessage(tx, event.TypeOrderConfirmation, toOrderConfirmation(*user, order, orderDetails))
+	if err != nil {
+		return model.Order{}, nil, err
+	}
+
+	return order, orderDetails, nil
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"final_project-ftgo-h8/pb"
+
 	"gorm.io/gorm"
 )
 
@@ -16,8 +18,37 @@ func NewUserRepository(db *gorm.DB) UserRepository{
 // order
 type orderRepository struct{
 	gormDb *gorm.DB
+	productService pb.ProductServiceClient
+}
+
+func NewOrderRepository(db *gorm.DB, productService pb.ProductServiceClient) OrderRepository{
+	return &orderRepository{gormDb: db, productService: productService}
+}
+
+// outbox
+type outboxRepository struct {
+	gormDb *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{gormDb: db}
+}
+
+// seller
+type sellerRepository struct {
+	gormDb *gorm.DB
+}
+
+func NewSellerRepository(db *gorm.DB) SellerRepository {
+	return &sellerRepository{gormDb: db}
+}
+
+// cart
+type cartRepository struct {
+	gormDb         *gorm.DB
+	productService pb.ProductServiceClient
 }
 
-func NewOrderRepository(db *gorm.DB) OrderRepository{
-	return &orderRepository{gormDb: db}
+func NewCartRepository(db *gorm.DB, productService pb.ProductServiceClient) CartRepository {
+	return &cartRepository{gormDb: db, productService: productService}
 }
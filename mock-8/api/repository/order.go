@@ -1,68 +1,103 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"final_project-ftgo-h8/api/dto"
 	"final_project-ftgo-h8/api/model"
+	"final_project-ftgo-h8/event"
+	"final_project-ftgo-h8/pb"
+	"final_project-ftgo-h8/quantity"
+	"fmt"
 	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var (
+	ErrInvalidOrderStatusTransition = errors.New("invalid order status transition")
+	ErrQuantityIncrement            = errors.New("quantity does not match the order increment")
+	ErrInsufficientFunds            = errors.New("insufficient funds")
+)
 
-func (r *orderRepository) InsertOrderAndDetail(reqBody dto.ReqBodyNewOrder, userId uint) (model.OrderDetail,error) {
-	// init user
-	var user model.User
-	result := r.gormDb.First(&user,userId)
-	if result.Error != nil{
-		return model.OrderDetail{},result.Error
+
+func (r *orderRepository) InsertOrderAndDetail(ctx context.Context, reqBody dto.ReqBodyNewOrder, userId uint) (model.OrderDetail,error) {
+	// reserve product stock in product-service
+	reservationId := newReservationId(userId)
+	reservation,err := r.productService.ReserveStock(ctx, &pb.ReserveStockRequest{
+		ReservationId: reservationId,
+		Items: []*pb.StockItem{toPbStockItem(reqBody.ProductId, reqBody.Quantity)},
+	})
+	if err != nil {
+		return model.OrderDetail{},err
 	}
 
-	// init product
-	var product model.Product
-	result = r.gormDb.First(&product,reqBody.ProductId)
-	if result.Error != nil{
+	// init product, the order belongs to the seller of the product
+	var seller model.Product
+	result := r.gormDb.WithContext(ctx).Select("id", "seller_id").First(&seller, reqBody.ProductId)
+	if result.Error != nil {
+		releaseStock(ctx, r.productService, reservationId)
 		return model.OrderDetail{},result.Error
 	}
+	product := model.Product{
+		ID: reqBody.ProductId,
+		Name: reservation.Items[0].GetName(),
+		Price: reservation.Items[0].GetPrice(),
+		Unit: reservation.Items[0].GetUnit(),
+		SellerId: seller.SellerId,
+	}
 	
-	// init total price
-	totalPrice := product.Price * int64(reqBody.Quantity)
+	// init total price, price is per unit of the product
+	totalPrice, err := reqBody.Quantity.Total(product.Price)
+	if err != nil {
+		releaseStock(ctx, r.productService, reservationId)
+		return model.OrderDetail{},err
+	}
 
 	// init and start gorm transaction
-	tx := r.gormDb.Begin()
-
-	// check & update product stock
-	product.Stock -= reqBody.Quantity
-	if product.Stock < 0 {
-		return model.OrderDetail{},errors.New("product stock is unavailable")
-	}
+	tx := r.gormDb.WithContext(ctx).Begin()
 
-	result = tx.Save(&product)
-	if result.Error != nil {
-		tx.Rollback()
+	// lock user
+	var user model.User
+	result = tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user,userId)
+	if result.Error != nil{
+		rollbackOrder(tx, r.productService, reservationId)
 		return model.OrderDetail{},result.Error
 	}
 	
-	// check & update user amount
-	user.Amount -= totalPrice
-	if user.Amount < 0{
-		tx.Rollback()
-		return model.OrderDetail{},errors.New("insufficient funds")
+	// create order, pending until it is paid
+	order := model.Order{
+		UserId: user.Id,
+		OrderDate: time.Now(),
+		Status: model.OrderStatusPending,
+		TotalPrice: totalPrice,
+		ReservationId: reservationId,
+		SellerId: product.SellerId,
 	}
-	
-	result = tx.Save(&user)
+
+	result = tx.Create(&order)
 	if result.Error != nil {
-		tx.Rollback()
+		rollbackOrder(tx, r.productService, reservationId)
 		return model.OrderDetail{},result.Error
 	}
 
-	// create order
-	order := model.Order{
-		UserId: user.Id,
-		OrderDate: time.Now(),
+	err = insertOrderStatusLog(tx, order.Id, order.Status, order.OrderDate)
+	if err != nil {
+		rollbackOrder(tx, r.productService, reservationId)
+		return model.OrderDetail{},err
 	}
 
-	result = tx.Create(&order)
+	// pay order from user amount
+	err = payOrder(tx, &user, &order)
+	if err != nil {
+		rollbackOrder(tx, r.productService, reservationId)
+		return model.OrderDetail{},err
+	}
+
+	result = tx.Save(&user)
 	if result.Error != nil {
-		tx.Rollback()
+		rollbackOrder(tx, r.productService, reservationId)
 		return model.OrderDetail{},result.Error
 	}
 
@@ -76,13 +111,23 @@ func (r *orderRepository) InsertOrderAndDetail(reqBody dto.ReqBodyNewOrder, user
 		Quantity: reqBody.Quantity,
 	}
 
-	result = tx.Create(&orderDetail)
+	result = tx.Omit("Order", "Product").Create(&orderDetail)
 	if result.Error != nil {
-		tx.Rollback()
+		rollbackOrder(tx, r.productService, reservationId)
 		return model.OrderDetail{},result.Error
 	}
 
-	tx.Commit()
+	// send order confirmation
+	err = insertOutboxMessage(tx, event.TypeOrderConfirmation, toOrderConfirmation(user, order, []model.OrderDetail{orderDetail}))
+	if err != nil {
+		rollbackOrder(tx, r.productService, reservationId)
+		return model.OrderDetail{},err
+	}
+
+	err = commitOrder(tx, r.productService, reservationId)
+	if err != nil {
+		return model.OrderDetail{},err
+	}
 	
 	return orderDetail,nil
 }
@@ -109,3 +154,246 @@ func (r *orderRepository) FindOrderDetails(userId uint) ([]model.OrderDetail,err
 	return orderDetailsResult,nil
 }
 
+func (r *orderRepository) FindOrderStatusLogs(orderId uint, userId uint) ([]model.OrderStatusLog, error) {
+	// check order owner
+	var order model.Order
+	result := r.gormDb.Where("id = ? and user_id = ?", orderId, userId).First(&order)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	// find
+	orderStatusLogs := []model.OrderStatusLog{}
+	result = r.gormDb.Where("order_id = ?", orderId).Order("changed_at, id").Find(&orderStatusLogs)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return orderStatusLogs, nil
+}
+
+func (r *orderRepository) CancelOrder(ctx context.Context, orderId uint, userId uint) (model.Order, error) {
+	// init and start gorm transaction
+	tx := r.gormDb.WithContext(ctx).Begin()
+
+	// lock order owned by user
+	var order model.Order
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? and user_id = ?", orderId, userId).First(&order)
+	if result.Error != nil {
+		tx.Rollback()
+		return model.Order{}, result.Error
+	}
+
+	err := cancelOrder(tx, &order)
+	if err != nil {
+		tx.Rollback()
+		return model.Order{}, err
+	}
+
+	result = tx.Commit()
+	if result.Error != nil {
+		return model.Order{}, result.Error
+	}
+
+	return order, nil
+}
+
+func (r *orderRepository) UpdateOrderStatus(ctx context.Context, orderId uint, status string) (model.Order, error) {
+	// init and start gorm transaction
+	tx := r.gormDb.WithContext(ctx).Begin()
+
+	// lock order
+	var order model.Order
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, orderId)
+	if result.Error != nil {
+		tx.Rollback()
+		return model.Order{}, result.Error
+	}
+
+	// cancellation also returns stock and amount
+	if status == model.OrderStatusCancelled {
+		err := cancelOrder(tx, &order)
+		if err != nil {
+			tx.Rollback()
+			return model.Order{}, err
+		}
+	} else {
+		err := changeOrderStatus(tx, &order, status)
+		if err != nil {
+			tx.Rollback()
+			return model.Order{}, err
+		}
+	}
+
+	// tell the buyer about the shipment
+	if order.Status == model.OrderStatusShipped || order.Status == model.OrderStatusDelivered {
+		var user model.User
+		result = tx.First(&user, order.UserId)
+		if result.Error != nil {
+			tx.Rollback()
+			return model.Order{}, result.Error
+		}
+
+		err := insertOutboxMessage(tx, event.TypeShipmentUpdate, event.ShipmentUpdate{
+			Email:   user.Email,
+			Name:    user.Name,
+			OrderId: order.Id,
+			Status:  order.Status,
+		})
+		if err != nil {
+			tx.Rollback()
+			return model.Order{}, err
+		}
+	}
+
+	result = tx.Commit()
+	if result.Error != nil {
+		return model.Order{}, result.Error
+	}
+
+	return order, nil
+}
+
+// cancelOrder refunds the order total to the buyer and queues the return of its stock to product-service.
+// tx must hold a lock on the order.
+func cancelOrder(tx *gorm.DB, order *model.Order) error {
+	err := changeOrderStatus(tx, order, model.OrderStatusCancelled)
+	if err != nil {
+		return err
+	}
+
+	// init order details
+	var orderDetails []model.OrderDetail
+	result := tx.Where("order_id = ?", order.Id).Find(&orderDetails)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	var refund int64
+	for _, v := range orderDetails {
+		refund += v.TotalPrice
+	}
+
+	// lock & refund user amount
+	var user model.User
+	result = tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, order.UserId)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	user.Amount += refund
+	result = tx.Save(&user)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	err = insertWalletTransaction(tx, user, model.WalletTransaction{
+		Type:    model.WalletTransactionRefund,
+		Amount:  refund,
+		OrderId: &order.Id,
+	})
+	if err != nil {
+		return err
+	}
+
+	// return product stock once the cancellation is committed, orders placed before stock reservations have nothing to return
+	if order.ReservationId == "" {
+		return nil
+	}
+	return insertOutboxMessageTo(tx, event.ProductServiceQueue, event.TypeStockCancel, event.StockCancel{ReservationId: order.ReservationId})
+}
+
+// payOrder takes the total of a pending order from the user amount and marks it paid,
+// the caller saves the user.
+func payOrder(tx *gorm.DB, user *model.User, order *model.Order) error {
+	// check & update user amount
+	user.Amount -= order.TotalPrice
+	if user.Amount < 0 {
+		return ErrInsufficientFunds
+	}
+
+	err := insertWalletTransaction(tx, *user, model.WalletTransaction{
+		Type:    model.WalletTransactionPurchase,
+		Amount:  -order.TotalPrice,
+		OrderId: &order.Id,
+	})
+	if err != nil {
+		return err
+	}
+
+	return changeOrderStatus(tx, order, model.OrderStatusPaid)
+}
+
+func changeOrderStatus(tx *gorm.DB, order *model.Order, status string) error {
+	// validate transition
+	if !model.CanTransitionOrderStatus(order.Status, status) {
+		return fmt.Errorf("%w: cannot change order status from %s to %s", ErrInvalidOrderStatusTransition, order.Status, status)
+	}
+
+	// update
+	result := tx.Model(order).Update("status", status)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return insertOrderStatusLog(tx, order.Id, status, time.Now())
+}
+
+func insertOrderStatusLog(tx *gorm.DB, orderId uint, status string, changedAt time.Time) error {
+	// model
+	orderStatusLog := model.OrderStatusLog{
+		OrderId:   orderId,
+		Status:    status,
+		ChangedAt: changedAt,
+	}
+
+	// create
+	result := tx.Create(&orderStatusLog)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return nil
+}
+
+func toOrderConfirmation(user model.User, order model.Order, orderDetails []model.OrderDetail) event.OrderConfirmation {
+	orderConfirmation := event.OrderConfirmation{
+		Email:     user.Email,
+		Name:      user.Name,
+		OrderId:   order.Id,
+		OrderedAt: order.OrderDate.Format(time.DateTime),
+	}
+	for _, v := range orderDetails {
+		orderConfirmation.Items = append(orderConfirmation.Items, event.OrderItem{
+			ProductName: v.Product.Name,
+			Quantity:    v.Quantity,
+			Unit:        v.Product.Unit,
+			TotalPrice:  v.TotalPrice,
+		})
+		orderConfirmation.TotalPrice += v.TotalPrice
+	}
+
+	return orderConfirmation
+}
+
+// checkOrderIncrement rejects quantities that are not a whole number of order steps of the product,
+// or too large for the order line or its total price
+func checkOrderIncrement(product model.Product, q quantity.Quantity) error {
+	if q > quantity.Max {
+		return quantity.ErrTooLarge
+	}
+	if _, err := q.Total(product.Price); err != nil {
+		return err
+	}
+
+	// products created before units existed are ordered per piece
+	increment := product.OrderIncrement
+	if increment <= 0 {
+		increment = quantity.FromInt(1)
+	}
+
+	if !q.IsMultipleOf(increment) {
+		return fmt.Errorf("%w: %s is sold in steps of %s %s", ErrQuantityIncrement, product.Name, increment, product.Unit)
+	}
+	return nil
+}
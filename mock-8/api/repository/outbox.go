@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"final_project-ftgo-h8/api/model"
+	"final_project-ftgo-h8/event"
+	"final_project-ftgo-h8/tracing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// insertOutboxMessage saves an email event in tx, it is published once tx is committed.
+func insertOutboxMessage(tx *gorm.DB, eventType string, payload interface{}) error {
+	return insertOutboxMessageTo(tx, event.EmailNotificationQueue, eventType, payload)
+}
+
+// insertOutboxMessageTo saves an event for queue in tx.
+func insertOutboxMessageTo(tx *gorm.DB, queue string, eventType string, payload interface{}) error {
+	msgByte, err := event.Marshal(eventType, payload)
+	if err != nil {
+		return err
+	}
+
+	// model
+	outboxMessage := model.OutboxMessage{
+		Queue:       queue,
+		EventType:   eventType,
+		Payload:     msgByte,
+		TraceParent: tracing.TraceParent(tx.Statement.Context),
+		CreatedAt:   time.Now(),
+	}
+
+	// create
+	result := tx.Create(&outboxMessage)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *outboxRepository) RelayOutboxMessages(limit int, publish func(model.OutboxMessage) error) (int, error) {
+	// init and start gorm transaction
+	tx := r.gormDb.Begin()
+
+	// lock the oldest pending messages, other relays skip them
+	var outboxMessages []model.OutboxMessage
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("sent_at is null").Order("id").Limit(limit).Find(&outboxMessages)
+	if result.Error != nil {
+		tx.Rollback()
+		return 0, result.Error
+	}
+
+	sent := 0
+	for _, v := range outboxMessages {
+		// stop at the first failure so events keep their order
+		err := publish(v)
+		if err != nil {
+			result = tx.Model(&v).Updates(map[string]interface{}{
+				"attempts":   gorm.Expr("attempts + 1"),
+				"last_error": err.Error(),
+			})
+			if result.Error != nil {
+				tx.Rollback()
+				return sent, result.Error
+			}
+			break
+		}
+
+		result = tx.Model(&v).Update("sent_at", time.Now())
+		if result.Error != nil {
+			tx.Rollback()
+			return sent, result.Error
+		}
+		sent++
+	}
+
+	result = tx.Commit()
+	if result.Error != nil {
+		return sent, result.Error
+	}
+
+	return sent, nil
+}
+
+func (r *outboxRepository) FindOutboxStats() (model.OutboxStats, error) {
+	var stats model.OutboxStats
+
+	// count pending
+	result := r.gormDb.Model(&model.OutboxMessage{}).Where("sent_at is null").Count(&stats.Pending)
+	if result.Error != nil {
+		return model.OutboxStats{}, result.Error
+	}
+	if stats.Pending == 0 {
+		return stats, nil
+	}
+
+	// oldest pending
+	var oldest model.OutboxMessage
+	result = r.gormDb.Where("sent_at is null").Order("id").First(&oldest)
+	if result.Error != nil {
+		return model.OutboxStats{}, result.Error
+	}
+	stats.OldestPendingAt = &oldest.CreatedAt
+	stats.LagSeconds = time.Since(oldest.CreatedAt).Seconds()
+
+	return stats, nil
+}
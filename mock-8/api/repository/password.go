@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"errors"
+	"final_project-ftgo-h8/api/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInvalidPasswordResetToken = errors.New("invalid or expired password reset token")
+
+func (r *userRepository) InsertPasswordReset(userId uint, tokenHash string, expiresAt time.Time) error {
+	// model
+	passwordReset := model.PasswordReset{
+		UserId:    userId,
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}
+
+	// create
+	result := r.gormDb.Create(&passwordReset)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return nil
+}
+
+// ResetPassword uses the reset token once, saves the new password hash
+// and revokes every refresh token of the user.
+func (r *userRepository) ResetPassword(tokenHash string, passwordHash string) (model.User, error) {
+	// init and start gorm transaction
+	tx := r.gormDb.Begin()
+	now := time.Now()
+
+	// lock unused reset token
+	passwordReset := model.PasswordReset{}
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("token_hash = ? and used_at is null", tokenHash).First(&passwordReset)
+	if result.Error != nil {
+		tx.Rollback()
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return model.User{}, ErrInvalidPasswordResetToken
+		}
+		return model.User{}, result.Error
+	}
+	if now.After(passwordReset.ExpiresAt) {
+		tx.Rollback()
+		return model.User{}, ErrInvalidPasswordResetToken
+	}
+
+	// use every outstanding reset token of the user
+	result = tx.Model(&model.PasswordReset{}).Where("user_id = ? and used_at is null", passwordReset.UserId).Update("used_at", now)
+	if result.Error != nil {
+		tx.Rollback()
+		return model.User{}, result.Error
+	}
+
+	// update password only, concurrent top-ups and checkouts change the amount of the same row
+	result = tx.Model(&model.User{}).Where("id = ?", passwordReset.UserId).Updates(map[string]interface{}{
+		"password":            passwordHash,
+		"password_changed_at": now,
+	})
+	if result.Error != nil {
+		tx.Rollback()
+		return model.User{}, result.Error
+	}
+
+	user := model.User{}
+	result = tx.First(&user, passwordReset.UserId)
+	if result.Error != nil {
+		tx.Rollback()
+		return model.User{}, result.Error
+	}
+
+	// invalidate sessions
+	result = tx.Model(&model.RefreshToken{}).Where("user_id = ? and revoked_at is null", user.Id).Update("revoked_at", now)
+	if result.Error != nil {
+		tx.Rollback()
+		return model.User{}, result.Error
+	}
+
+	result = tx.Commit()
+	if result.Error != nil {
+		return model.User{}, result.Error
+	}
+
+	return user, nil
+}
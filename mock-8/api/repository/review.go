@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"final_project-ftgo-h8/api/dto"
+	"final_project-ftgo-h8/api/model"
+	"final_project-ftgo-h8/pb"
+	"strconv"
+)
+
+var ErrOrderNotDelivered = errors.New("only delivered orders can be reviewed")
+
+func (r *orderRepository) InsertReview(ctx context.Context, orderId uint, userId uint, reqBody dto.ReqBodyNewReview) (*pb.Review, error) {
+	// find the order line of the product owned by user
+	var orderDetail model.OrderDetail
+	result := r.gormDb.WithContext(ctx).Preload("Order").
+		Joins("JOIN orders ON orders.id = order_details.order_id").
+		Where("order_details.order_id = ? and order_details.product_id = ? and orders.user_id = ?", orderId, reqBody.ProductId, userId).
+		First(&orderDetail)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	// the buyer must have received the product
+	if orderDetail.Order.Status != model.OrderStatusDelivered {
+		return nil, ErrOrderNotDelivered
+	}
+
+	// product-service keeps one review per order line
+	return r.productService.CreateReview(ctx, &pb.CreateReviewRequest{
+		Review: &pb.Review{
+			ProductId:     strconv.FormatUint(uint64(orderDetail.ProductId), 10),
+			UserId:        strconv.FormatUint(uint64(userId), 10),
+			OrderDetailId: strconv.FormatUint(uint64(orderDetail.Id), 10),
+			Rating:        reqBody.Rating,
+			Body:          reqBody.Body,
+		},
+	})
+}
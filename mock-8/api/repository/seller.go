@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"final_project-ftgo-h8/api/dto"
+	"final_project-ftgo-h8/api/model"
+)
+
+func (r *sellerRepository) FindSellerProfile(userId uint) (model.SellerProfile, error) {
+	// find
+	var profile model.SellerProfile
+	result := r.gormDb.First(&profile, userId)
+	if result.Error != nil {
+		return model.SellerProfile{}, result.Error
+	}
+
+	return profile, nil
+}
+
+func (r *sellerRepository) UpdateSellerProfile(userId uint, reqBody dto.ReqBodySellerProfile) (model.SellerProfile, error) {
+	// find
+	var profile model.SellerProfile
+	result := r.gormDb.First(&profile, userId)
+	if result.Error != nil {
+		return model.SellerProfile{}, result.Error
+	}
+
+	// update
+	profile.StoreName = reqBody.StoreName
+	profile.Location = reqBody.Location
+	profile.BankName = reqBody.BankName
+	profile.BankAccountNumber = reqBody.BankAccountNumber
+	profile.BankAccountName = reqBody.BankAccountName
+	result = r.gormDb.Save(&profile)
+	if result.Error != nil {
+		return model.SellerProfile{}, result.Error
+	}
+
+	return profile, nil
+}
+
+func (r *sellerRepository) FindSellerOrders(sellerId uint, statuses []string) ([]model.Order, []model.OrderDetail, error) {
+	// find orders of the seller with the buyer to ship to, oldest first
+	orders := []model.Order{}
+	result := r.gormDb.Preload("Buyer").Where("seller_id = ? and status in ?", sellerId, statuses).Order("order_date, id").Find(&orders)
+	if result.Error != nil {
+		return nil, nil, result.Error
+	}
+	if len(orders) == 0 {
+		return orders, []model.OrderDetail{}, nil
+	}
+
+	// find the lines of the orders
+	orderIds := make([]uint, 0, len(orders))
+	for _, v := range orders {
+		orderIds = append(orderIds, v.Id)
+	}
+	orderDetails := []model.OrderDetail{}
+	result = r.gormDb.Preload("Product").Where("order_id in ?", orderIds).Order("id").Find(&orderDetails)
+	if result.Error != nil {
+		return nil, nil, result.Error
+	}
+
+	return orders, orderDetails, nil
+}
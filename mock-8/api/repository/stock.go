@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"final_project-ftgo-h8/helper"
+	"final_project-ftgo-h8/pb"
+	"final_project-ftgo-h8/quantity"
+	"final_project-ftgo-h8/tracing"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func newReservationId(userId uint) string {
+	return fmt.Sprintf("order-%d-%d", userId, time.Now().UnixNano())
+}
+
+func toPbStockItem(productId uint, q quantity.Quantity) *pb.StockItem {
+	return &pb.StockItem{
+		ProductId: strconv.FormatUint(uint64(productId), 10),
+		Quantity:  int64(q),
+	}
+}
+
+// commitOrder commits the stock reservations and then the order transaction,
+// the reservations are cancelled again when the order can not be saved.
+// product-service is called with the context of tx, detached so a cancelled request can not
+// leave reservations committed without their order.
+func commitOrder(tx *gorm.DB, productService pb.ProductServiceClient, reservationIds ...string) error {
+	ctx := tracing.Detach(tx.Statement.Context)
+	for _, reservationId := range reservationIds {
+		_, err := productService.CommitReservation(ctx, &pb.CommitReservationRequest{ReservationId: reservationId})
+		if err != nil {
+			// reservations committed so far are cancelled with the rest
+			tx.Rollback()
+			cancelStock(ctx, productService, reservationIds...)
+			return err
+		}
+	}
+
+	result := tx.Commit()
+	if result.Error != nil {
+		cancelStock(ctx, productService, reservationIds...)
+		return result.Error
+	}
+
+	return nil
+}
+
+// rollbackOrder rolls back the order transaction and returns the reserved stock.
+func rollbackOrder(tx *gorm.DB, productService pb.ProductServiceClient, reservationIds ...string) {
+	tx.Rollback()
+	for _, reservationId := range reservationIds {
+		releaseStock(tx.Statement.Context, productService, reservationId)
+	}
+}
+
+// cancelStock returns the stock of reservations that may already be committed.
+func cancelStock(ctx context.Context, productService pb.ProductServiceClient, reservationIds ...string) {
+	for _, reservationId := range reservationIds {
+		err := cancelReservation(ctx, productService, reservationId)
+		if err != nil {
+			log.Printf("failed to cancel stock reservation %s: %v", reservationId, err)
+		}
+	}
+}
+
+// cancelReservation returns the stock of a reserved or committed reservation. Buyers can not
+// return committed stock, the gateway calls product-service as an admin for them.
+func cancelReservation(ctx context.Context, productService pb.ProductServiceClient, reservationId string) error {
+	ctx = helper.AppendGatewayMetadata(tracing.Detach(ctx))
+	_, err := productService.CancelReservation(ctx, &pb.CancelReservationRequest{ReservationId: reservationId})
+	return err
+}
+
+func releaseStock(ctx context.Context, productService pb.ProductServiceClient, reservationId string) {
+	_, err := productService.ReleaseReservation(tracing.Detach(ctx), &pb.ReleaseReservationRequest{ReservationId: reservationId})
+	if err != nil {
+		// the reservation ttl returns the stock if it was never committed
+		log.Printf("failed to release stock reservation %s: %v", reservationId, err)
+	}
+}
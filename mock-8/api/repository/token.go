@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"errors"
+	"final_project-ftgo-h8/api/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token has already been used")
+)
+
+func (r *userRepository) InsertRefreshToken(userId uint, familyId string, tokenHash string, expiresAt time.Time) (model.RefreshToken, error) {
+	// model
+	refreshToken := model.RefreshToken{
+		UserId:    userId,
+		FamilyId:  familyId,
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}
+
+	// create
+	result := r.gormDb.Create(&refreshToken)
+	if result.Error != nil {
+		return model.RefreshToken{}, result.Error
+	}
+
+	return refreshToken, nil
+}
+
+// RotateRefreshToken revokes the used refresh token and stores its replacement in the same family.
+// Presenting a token that was already rotated revokes the whole family.
+func (r *userRepository) RotateRefreshToken(tokenHash string, newTokenHash string, expiresAt time.Time) (model.RefreshToken, error) {
+	// init and start gorm transaction
+	tx := r.gormDb.Begin()
+
+	// lock used token
+	refreshToken := model.RefreshToken{}
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("token_hash = ?", tokenHash).First(&refreshToken)
+	if result.Error != nil {
+		tx.Rollback()
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return model.RefreshToken{}, ErrInvalidRefreshToken
+		}
+		return model.RefreshToken{}, result.Error
+	}
+
+	now := time.Now()
+
+	// reuse of a rotated token means it leaked
+	if refreshToken.RevokedAt != nil {
+		result = tx.Model(&model.RefreshToken{}).Where("family_id = ? and revoked_at is null", refreshToken.FamilyId).Update("revoked_at", now)
+		if result.Error != nil {
+			tx.Rollback()
+			return model.RefreshToken{}, result.Error
+		}
+		tx.Commit()
+		return model.RefreshToken{}, ErrRefreshTokenReused
+	}
+
+	if now.After(refreshToken.ExpiresAt) {
+		tx.Rollback()
+		return model.RefreshToken{}, ErrInvalidRefreshToken
+	}
+
+	// revoke used token
+	result = tx.Model(&refreshToken).Update("revoked_at", now)
+	if result.Error != nil {
+		tx.Rollback()
+		return model.RefreshToken{}, result.Error
+	}
+
+	// create replacement
+	newRefreshToken := model.RefreshToken{
+		UserId:    refreshToken.UserId,
+		FamilyId:  refreshToken.FamilyId,
+		TokenHash: newTokenHash,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}
+
+	result = tx.Create(&newRefreshToken)
+	if result.Error != nil {
+		tx.Rollback()
+		return model.RefreshToken{}, result.Error
+	}
+
+	result = tx.Commit()
+	if result.Error != nil {
+		return model.RefreshToken{}, result.Error
+	}
+
+	return newRefreshToken, nil
+}
+
+func (r *userRepository) RevokeRefreshTokenFamily(tokenHash string) error {
+	// find token
+	refreshToken := model.RefreshToken{}
+	result := r.gormDb.Where("token_hash = ?", tokenHash).First(&refreshToken)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return ErrInvalidRefreshToken
+		}
+		return result.Error
+	}
+
+	// revoke every token of the family
+	result = r.gormDb.Model(&model.RefreshToken{}).Where("family_id = ? and revoked_at is null", refreshToken.FamilyId).Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return nil
+}
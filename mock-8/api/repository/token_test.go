@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newMockUserRepository(t *testing.T) (*userRepository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	gormDb, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &userRepository{gormDb: gormDb}, mock
+}
+
+var refreshTokenColumns = []string{"id", "user_id", "family_id", "token_hash", "expires_at", "revoked_at", "created_at"}
+
+func TestRotateRefreshTokenReuseRevokesFamily(t *testing.T) {
+	r, mock := newMockUserRepository(t)
+	rotatedAt := time.Now().Add(-time.Minute)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "refresh_tokens" WHERE token_hash = \$1 .* FOR UPDATE`).
+		WithArgs("old-hash").
+		WillReturnRows(sqlmock.NewRows(refreshTokenColumns).
+			AddRow(1, 7, "family-1", "old-hash", time.Now().Add(time.Hour), rotatedAt, rotatedAt))
+	mock.ExpectExec(`UPDATE "refresh_tokens" SET "revoked_at"=\$1 WHERE family_id = \$2 and revoked_at is null`).
+		WithArgs(sqlmock.AnyArg(), "family-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	_, err := r.RotateRefreshToken("old-hash", "new-hash", time.Now().Add(time.Hour))
+	if !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("err = %v, want %v", err, ErrRefreshTokenReused)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRotateRefreshTokenExpired(t *testing.T) {
+	r, mock := newMockUserRepository(t)
+	createdAt := time.Now().Add(-8 * 24 * time.Hour)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "refresh_tokens" WHERE token_hash = \$1 .* FOR UPDATE`).
+		WithArgs("old-hash").
+		WillReturnRows(sqlmock.NewRows(refreshTokenColumns).
+			AddRow(1, 7, "family-1", "old-hash", time.Now().Add(-time.Hour), nil, createdAt))
+	mock.ExpectRollback()
+
+	_, err := r.RotateRefreshToken("old-hash", "new-hash", time.Now().Add(time.Hour))
+	if !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("err = %v, want %v", err, ErrInvalidRefreshToken)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRotateRefreshToken(t *testing.T) {
+	r, mock := newMockUserRepository(t)
+	createdAt := time.Now().Add(-time.Hour)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "refresh_tokens" WHERE token_hash = \$1 .* FOR UPDATE`).
+		WithArgs("old-hash").
+		WillReturnRows(sqlmock.NewRows(refreshTokenColumns).
+			AddRow(1, 7, "family-1", "old-hash", time.Now().Add(time.Hour), nil, createdAt))
+	mock.ExpectExec(`UPDATE "refresh_tokens" SET "revoked_at"=\$1 WHERE "id" = \$2`).
+		WithArgs(sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO "refresh_tokens"`).
+		WithArgs(7, "family-1", "new-hash", sqlmock.AnyArg(), nil, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectCommit()
+
+	token, err := r.RotateRefreshToken("old-hash", "new-hash", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Id != 2 || token.UserId != 7 || token.FamilyId != "family-1" || token.TokenHash != "new-hash" {
+		t.Errorf("token = %+v", token)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -1,21 +1,54 @@
 package repository
 
 import (
+	"context"
 	"final_project-ftgo-h8/api/dto"
 	"final_project-ftgo-h8/api/model"
+	"final_project-ftgo-h8/pb"
+	"final_project-ftgo-h8/quantity"
+	"time"
 )
 
 // user
 type UserRepository interface {
-	InsertUser(reqbody dto.ReqBodyRegister) (model.User, error)
+	InsertUserAndVerification(reqbody dto.ReqBodyRegister, code string) (model.User, error)
 	FindUserByEmail(email string) (model.User, error)
-	InsertUserVerification(userId uint, code string) error
 	UpdateUserStatusByIdAndCode(userId uint, code string) (model.User, error)
 	FindUserById(userId uint) (model.User, error)
-	UpdateAmount(user model.User, amount int64) (model.User, error)
+	UpdateAmount(userId uint, walletTransaction model.WalletTransaction) (model.User, error)
+	CreditTopUp(userId uint, topUpId string, amount int64) (model.User, bool, error)
+	FindWalletTransactions(userId uint, filter WalletTransactionFilter) ([]model.WalletTransaction, int64, error)
+	InsertRefreshToken(userId uint, familyId string, tokenHash string, expiresAt time.Time) (model.RefreshToken, error)
+	RotateRefreshToken(tokenHash string, newTokenHash string, expiresAt time.Time) (model.RefreshToken, error)
+	RevokeRefreshTokenFamily(tokenHash string) error
+	InsertPasswordReset(userId uint, tokenHash string, expiresAt time.Time) error
+	ResetPassword(tokenHash string, passwordHash string) (model.User, error)
 }
 
 type OrderRepository interface{
-	InsertOrderAndDetail(reqBody dto.ReqBodyNewOrder, userId uint) (model.OrderDetail,error)
+	InsertOrderAndDetail(ctx context.Context, reqBody dto.ReqBodyNewOrder, userId uint) (model.OrderDetail,error)
 	FindOrderDetails(userId uint) ([]model.OrderDetail,error)
+	FindOrderStatusLogs(orderId uint, userId uint) ([]model.OrderStatusLog, error)
+	CancelOrder(ctx context.Context, orderId uint, userId uint) (model.Order, error)
+	UpdateOrderStatus(ctx context.Context, orderId uint, status string) (model.Order, error)
+	InsertReview(ctx context.Context, orderId uint, userId uint, reqBody dto.ReqBodyNewReview) (*pb.Review, error)
+}
+
+type OutboxRepository interface {
+	RelayOutboxMessages(limit int, publish func(model.OutboxMessage) error) (int, error)
+	FindOutboxStats() (model.OutboxStats, error)
+}
+
+type SellerRepository interface {
+	FindSellerProfile(userId uint) (model.SellerProfile, error)
+	UpdateSellerProfile(userId uint, reqBody dto.ReqBodySellerProfile) (model.SellerProfile, error)
+	FindSellerOrders(sellerId uint, statuses []string) ([]model.Order, []model.OrderDetail, error)
+}
+
+type CartRepository interface {
+	FindCartItems(userId uint) ([]model.CartItem, error)
+	AddCartItem(userId uint, productId uint, q quantity.Quantity) (model.CartItem, error)
+	UpdateCartItem(userId uint, productId uint, q quantity.Quantity) (model.CartItem, error)
+	DeleteCartItem(userId uint, productId uint) error
+	Checkout(ctx context.Context, userId uint) ([]model.Order, []model.OrderDetail, error)
 }
\ No newline at end of file
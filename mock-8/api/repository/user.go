@@ -3,10 +3,13 @@ package repository
 import (
 	"final_project-ftgo-h8/api/dto"
 	"final_project-ftgo-h8/api/model"
+	"final_project-ftgo-h8/event"
 	"time"
+
+	"gorm.io/gorm/clause"
 )
 
-func (r *userRepository) InsertUser(reqBody dto.ReqBodyRegister) (model.User,error){
+func (r *userRepository) InsertUserAndVerification(reqBody dto.ReqBodyRegister, code string) (model.User,error){
 	// model
 	user := model.User{
 		Name: reqBody.Name,
@@ -19,29 +22,64 @@ func (r *userRepository) InsertUser(reqBody dto.ReqBodyRegister) (model.User,err
 		RegisteredAt: time.Now(),
 	}
 
-	// create
-	result := r.gormDb.Create(&user)
+	// init and start gorm transaction
+	tx := r.gormDb.Begin()
+
+	// create user
+	result := tx.Create(&user)
 	if result.Error != nil{
+		tx.Rollback()
 		return model.User{},result.Error
 	}
 
-	return user,nil
-}
+	// create seller profile
+	if reqBody.Seller != nil {
+		profile := model.SellerProfile{
+			UserId: user.Id,
+			StoreName: reqBody.Seller.StoreName,
+			Location: reqBody.Seller.Location,
+			BankName: reqBody.Seller.BankName,
+			BankAccountNumber: reqBody.Seller.BankAccountNumber,
+			BankAccountName: reqBody.Seller.BankAccountName,
+			CreatedAt: user.RegisteredAt,
+		}
+
+		result = tx.Create(&profile)
+		if result.Error != nil{
+			tx.Rollback()
+			return model.User{},result.Error
+		}
+	}
 
-func (r *userRepository) InsertUserVerification(userId uint, code string) error{
-	// model
+	// create user verification
 	userVerif := model.UserVerification{
-		UserID: userId,
+		UserID: user.Id,
+		VerificationCode: code,
+	}
+
+	result = tx.Create(&userVerif)
+	if result.Error != nil{
+		tx.Rollback()
+		return model.User{},result.Error
+	}
+
+	// send code with email notification
+	err := insertOutboxMessage(tx, event.TypeUserVerification, event.UserVerification{
+		Email: user.Email,
+		UserId: user.Id,
 		VerificationCode: code,
+	})
+	if err != nil {
+		tx.Rollback()
+		return model.User{},err
 	}
 
-	// create
-	result := r.gormDb.Create(&userVerif)
+	result = tx.Commit()
 	if result.Error != nil{
-		return result.Error
+		return model.User{},result.Error
 	}
 
-	return nil
+	return user,nil
 }
 
 func (r *userRepository) UpdateUserStatusByIdAndCode(userId uint, code string) (model.User,error){
@@ -96,10 +134,38 @@ func (r *userRepository) FindUserById(userId uint) (model.User,error){
 	return user,nil
 }
 
-func (r *userRepository) UpdateAmount(user model.User, amount int64) (model.User, error) {
-	user.Amount += amount
+func (r *userRepository) UpdateAmount(userId uint, walletTransaction model.WalletTransaction) (model.User, error) {
+	// init and start gorm transaction
+	tx := r.gormDb.Begin()
+
+	// lock user
+	user := model.User{}
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userId)
+	if result.Error != nil {
+		tx.Rollback()
+		return model.User{}, result.Error
+	}
+
+	// check & update user amount
+	user.Amount += walletTransaction.Amount
+	if user.Amount < 0 {
+		tx.Rollback()
+		return model.User{}, ErrInsufficientFunds
+	}
+
+	result = tx.Save(&user)
+	if result.Error != nil {
+		tx.Rollback()
+		return model.User{}, result.Error
+	}
+
+	err := insertWalletTransaction(tx, user, walletTransaction)
+	if err != nil {
+		tx.Rollback()
+		return model.User{}, err
+	}
 
-	result := r.gormDb.Save(&user)
+	result = tx.Commit()
 	if result.Error != nil {
 		return model.User{}, result.Error
 	}
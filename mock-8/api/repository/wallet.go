@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"final_project-ftgo-h8/api/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// filter and page for wallet transaction history
+type WalletTransactionFilter struct {
+	Limit  int
+	Offset int
+	From   time.Time
+	To     time.Time
+}
+
+func (r *userRepository) FindWalletTransactions(userId uint, filter WalletTransactionFilter) ([]model.WalletTransaction, int64, error) {
+	// build filtered query
+	query := r.gormDb.Model(&model.WalletTransaction{}).Where("user_id = ?", userId)
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+
+	// count
+	var total int64
+	result := query.Count(&total)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+
+	// find
+	walletTransactions := []model.WalletTransaction{}
+	result = query.Order("created_at desc, id desc").Limit(filter.Limit).Offset(filter.Offset).Find(&walletTransactions)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+
+	return walletTransactions, total, nil
+}
+
+// CreditTopUp adds a paid top-up to the user amount once, the top-up id is the idempotency key.
+// credited is false when the top-up was already added by an earlier notification.
+func (r *userRepository) CreditTopUp(userId uint, topUpId string, amount int64) (user model.User, credited bool, err error) {
+	// init and start gorm transaction
+	tx := r.gormDb.Begin()
+
+	// lock user, concurrent notifications of the same top-up wait here
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userId)
+	if result.Error != nil {
+		tx.Rollback()
+		return model.User{}, false, result.Error
+	}
+
+	// already credited
+	var count int64
+	result = tx.Model(&model.WalletTransaction{}).Where("top_up_id = ?", topUpId).Count(&count)
+	if result.Error != nil {
+		tx.Rollback()
+		return model.User{}, false, result.Error
+	}
+	if count > 0 {
+		tx.Rollback()
+		return user, false, nil
+	}
+
+	// update user amount
+	user.Amount += amount
+	result = tx.Save(&user)
+	if result.Error != nil {
+		tx.Rollback()
+		return model.User{}, false, result.Error
+	}
+
+	err = insertWalletTransaction(tx, user, model.WalletTransaction{
+		Type:    model.WalletTransactionTopUp,
+		Amount:  amount,
+		TopUpId: topUpId,
+	})
+	if err != nil {
+		tx.Rollback()
+		return model.User{}, false, err
+	}
+
+	result = tx.Commit()
+	if result.Error != nil {
+		return model.User{}, false, result.Error
+	}
+
+	return user, true, nil
+}
+
+// insertWalletTransaction records a change of user amount, user must hold the balance after the change.
+func insertWalletTransaction(tx *gorm.DB, user model.User, walletTransaction model.WalletTransaction) error {
+	walletTransaction.UserId = user.Id
+	walletTransaction.BalanceAfter = user.Amount
+	walletTransaction.CreatedAt = time.Now()
+
+	// create
+	result := tx.Create(&walletTransaction)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return nil
+}
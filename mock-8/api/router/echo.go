@@ -1,84 +1,195 @@
 package router
 
 import (
+	"context"
 	"final_project-ftgo-h8/api/controller"
 	"final_project-ftgo-h8/api/middleware"
 	"final_project-ftgo-h8/api/publisher"
 	"final_project-ftgo-h8/api/repository"
+	"final_project-ftgo-h8/api/storage"
 	"final_project-ftgo-h8/config"
+	"final_project-ftgo-h8/event"
+	"final_project-ftgo-h8/health"
+	"final_project-ftgo-h8/lifecycle"
+	"final_project-ftgo-h8/metrics"
 	"final_project-ftgo-h8/pb"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 )
 
-func NewEchoInstance() *echo.Echo{
+// NewEchoInstance builds the gateway, its connections and background jobs are added to app
+// so they are closed after the http server on shutdown.
+func NewEchoInstance(cfg *config.Config, app *lifecycle.Lifecycle) *echo.Echo{
 	// init echo
 	e := echo.New()
+	e.Use(middleware.Metrics)
+	e.Use(middleware.Tracing)
 	e.GET("/", func(c echo.Context) error {
 		return c.String(http.StatusOK, "Hello, World!")
 	})
 
 	// init db
-	gormDb := config.NewGorm()
+	gormDb := config.NewGorm(cfg.Database)
+	sqlDb, err := gormDb.DB()
+	if err != nil {
+		log.Fatalf("Failed to get database connection: %v", err)
+	}
+	app.Add(lifecycle.Closer("postgres", sqlDb.Close))
+
+	// init gRPC connection
+	grpcConn, err := grpc.Dial(cfg.ProductService.Target, grpc.WithInsecure(), grpc.WithChainUnaryInterceptor(metrics.UnaryClientInterceptor, otelgrpc.UnaryClientInterceptor()))
+	if err != nil {
+		log.Fatalf("Failed to connect to gRPC server: %v", err)
+	}
+	app.Add(lifecycle.Closer("product-service connection", grpcConn.Close))
+
+	// init gRPC client
+	grpcClient := pb.NewProductServiceClient(grpcConn)
 
 	// init repository
 	userRepository := repository.NewUserRepository(gormDb)
-	orderRepository := repository.NewOrderRepository(gormDb)
+	orderRepository := repository.NewOrderRepository(gormDb, grpcClient)
+	cartRepository := repository.NewCartRepository(gormDb, grpcClient)
+	outboxRepository := repository.NewOutboxRepository(gormDb)
+	sellerRepository := repository.NewSellerRepository(gormDb)
+
+	// init mongodb, top-ups are stored there
+	mongoDb := config.NewMongoDB(cfg.MongoDB)
+	app.Add(lifecycle.Component{Name: "mongodb", Stop: mongoDb.Client().Disconnect})
 
 	// init chan
-	channel := config.NewChannel()
+	conn := config.NewConnection(cfg.RabbitMQ)
+	app.Add(lifecycle.Closer("rabbitmq", conn.Close))
+	channel := config.NewChannel(conn)
 
 	// add queue for email notification
-	_ = config.AddQueue(channel, "fishlink-email_notification")
+	emailQueue := config.AddQueue(channel, event.EmailNotificationQueue)
+	metrics.RegisterQueueDepth(conn, emailQueue.Name)
 
 	// init publisher
 	emailNotification := publisher.NewPublisher(channel)
 
+	// start outbox relay, stock of cancelled orders is returned to product-service over gRPC
+	outboxPublisher := publisher.NewRoutedPublisher(emailNotification, map[string]publisher.Publisher{
+		event.ProductServiceQueue: publisher.NewProductServicePublisher(grpcClient),
+	})
+	outboxRelay := publisher.NewOutboxRelay(outboxRepository, outboxPublisher)
+	metrics.RegisterOutbox(func() (int64, *time.Time, error) {
+		stats, err := outboxRepository.FindOutboxStats()
+		return stats.Pending, stats.OldestPendingAt, err
+	})
+	app.Add(lifecycle.Component{
+		Name: "outbox relay",
+		Start: func(ctx context.Context) error {
+			outboxRelay.Run(ctx, time.Second)
+			return nil
+		},
+	})
+
 	// init controller
-	userController := controller.NewUserController(userRepository, emailNotification)
+	userController := controller.NewUserController(userRepository, emailNotification, mongoDb, cfg.API.SecretSign, cfg.Midtrans.ServerKey)
 	orderController := controller.NewOrderController(orderRepository)
+	cartController := controller.NewCartController(cartRepository)
+	outboxController := controller.NewOutboxController(outboxRelay)
+	sellerController := controller.NewSellerController(sellerRepository)
+
+	// init health checks, /readyz fails while a dependency is down
+	checker := health.NewChecker(cfg.HealthCheckTimeout)
+	checker.Add("postgres", health.SQL(sqlDb))
+	checker.Add("mongodb", health.MongoDB(mongoDb.Client()))
+	checker.Add("rabbitmq", health.RabbitMQ(conn, channel))
+	checker.Add("product-service", health.GRPC(grpcConn, pb.ProductService_ServiceDesc.ServiceName))
+	e.GET("/healthz", echo.WrapHandler(health.LivenessHandler()))
+	e.GET("/readyz", echo.WrapHandler(checker.ReadinessHandler()))
 
 	// init authentication middleware
-	authMiddleware := middleware.NewAuthenticationMiddleware(userRepository)
-
-	// init gRPC connection
-	grpcConn, err := grpc.Dial("localhost:50051", grpc.WithInsecure())
-	if err != nil {
-		log.Fatalf("Failed to connect to gRPC server: %v", err)
-	}
-	// defer grpcConn.Close()
+	authMiddleware := middleware.NewAuthenticationMiddleware(userRepository, cfg.API.SecretSign)
 
-	// init gRPC client
-	grpcClient := pb.NewProductServiceClient(grpcConn)
+	// init storage for uploaded files, served by the gateway
+	fileStorage := storage.NewLocalStorage(cfg.API.UploadDir, cfg.API.UploadBaseURL)
+	e.Static("/uploads", cfg.API.UploadDir)
 
 	// init ProductController with the gRPC client
-	productController := controller.NewProductController(grpcClient)
+	productController := controller.NewProductController(grpcClient, fileStorage, userRepository)
 
 	// user gateaway (before login)
 	e.POST("/register", userController.Register)
 	e.POST("/login", userController.Login)
+	e.POST("/refresh", userController.Refresh)
+	e.POST("/logout", userController.Logout)
+	e.POST("/password/forgot", userController.ForgotPassword)
+	e.POST("/password/reset", userController.ResetPassword)
 	e.GET("user-verification-register/:id/:code", userController.RegisterVerification)
+	e.GET("/product/search", productController.SearchProducts)
 	e.GET("/product/:id", productController.GetProduct)
+	e.GET("/product/:id/reviews", productController.GetProductReviews)
 	e.GET("/product", productController.GetAllProducts)
+	e.GET("/category", productController.GetAllCategories)
+	e.GET("/category/:slug/products", productController.GetCategoryProducts)
+
+	// payment gateway notification
+	e.POST("/payments/midtrans/notification", userController.MidtransNotification)
 
 	// user route (after login)
 	user := e.Group("/user", authMiddleware.Authentication)
 	{
 		user.GET("/info", userController.GetInfo)
 		user.PUT("/top-up", userController.TopUp)
+		user.GET("/wallet/transactions", userController.GetWalletTransactions)
 		user.POST("/order",orderController.NewOrder)
 		user.GET("/order",orderController.GetOrders)
+		user.GET("/order/:id/status", orderController.GetOrderStatusLogs)
+		user.POST("/order/:id/cancel", orderController.CancelOrder)
+		user.POST("/order/:id/review", orderController.ReviewOrder)
+		user.GET("/cart", cartController.GetCart)
+		user.POST("/cart", cartController.AddCartItem)
+		user.PUT("/cart/:product_id", cartController.UpdateCartItem)
+		user.DELETE("/cart/:product_id", cartController.DeleteCartItem)
+		user.POST("/cart/checkout", cartController.Checkout)
+		user.POST("/product/:id/restock-subscription", productController.SubscribeRestock)
+		user.DELETE("/product/:id/restock-subscription", productController.UnsubscribeRestock)
+	}
+
+	// admin route
+	admin := e.Group("/admin", authMiddleware.AuthAdmin)
+	{
+		admin.PUT("/order/:id/status", orderController.UpdateOrderStatus)
+		admin.POST("/user/:id/wallet/adjustment", userController.AdjustAmount)
+		admin.GET("/outbox/stats", outboxController.GetOutboxStats)
+		admin.PUT("/review/:id", productController.ModerateReview)
+		admin.DELETE("/review/:id", productController.DeleteReview)
+	}
+
+	// seller route
+	seller := e.Group("/seller", authMiddleware.AuthSeller)
+	{
+		seller.GET("/profile", sellerController.GetProfile)
+		seller.PUT("/profile", sellerController.UpdateProfile)
+		seller.GET("/orders", sellerController.GetOrders)
 	}
 
-	// product route - admin
-	product := e.Group("/product")
+	// product route - admin and seller
+	product := e.Group("/product", authMiddleware.AuthAdminOrSeller)
 	{
 		product.POST("", productController.CreateProduct)
 		product.PUT("/:id", productController.UpdateProduct)
 		product.DELETE("/:id", productController.DeleteProduct)
+		product.POST("/:id/images", productController.UploadProductImages, middleware.BodyLimit(controller.MaxImageUploadSize))
+		product.POST("/:id/lots", productController.AddStockLot)
+		product.GET("/:id/lots", productController.GetStockLots)
+	}
+
+	// category route - admin
+	category := e.Group("/category", authMiddleware.AuthAdmin)
+	{
+		category.POST("", productController.CreateCategory)
+		category.PUT("/:id", productController.UpdateCategory)
+		category.DELETE("/:id", productController.DeleteCategory)
 	}
 
 	return e
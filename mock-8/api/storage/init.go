@@ -0,0 +1,23 @@
+package storage
+
+import (
+	"context"
+	"io"
+)
+
+// Storage keeps uploaded files under a key such as products/1/abc.jpg and returns their public url.
+// The local filesystem is used for now, an S3 compatible bucket can implement the same interface.
+type Storage interface {
+	Save(ctx context.Context, key string, r io.Reader, contentType string) (string, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// local filesystem
+type localStorage struct {
+	dir     string
+	baseURL string
+}
+
+func NewLocalStorage(dir string, baseURL string) Storage {
+	return &localStorage{dir: dir, baseURL: baseURL}
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// path of key inside the storage dir, keys can not leave the dir
+func (s *localStorage) path(key string) (string, error) {
+	cleanKey := path.Clean("/" + key)
+	if cleanKey == "/" || strings.Contains(key, "..") {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(cleanKey)), nil
+}
+
+func (s *localStorage) Save(ctx context.Context, key string, r io.Reader, contentType string) (string, error) {
+	filePath, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+
+	// create dir
+	err = os.MkdirAll(filepath.Dir(filePath), 0o755)
+	if err != nil {
+		return "", err
+	}
+
+	// write to a temp file first so a failed upload never leaves a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, r)
+	if err != nil {
+		tmp.Close()
+		return "", err
+	}
+	err = tmp.Close()
+	if err != nil {
+		return "", err
+	}
+
+	err = os.Rename(tmp.Name(), filePath)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(s.baseURL, "/") + path.Clean("/"+key), nil
+}
+
+func (s *localStorage) Delete(ctx context.Context, key string) error {
+	filePath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(filePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// isolate clears every config env var and runs Load away from any .env file
+func isolate(t *testing.T) {
+	walkFields(reflect.ValueOf(&Config{}).Elem(), "", func(f reflect.Value, key string, tag reflect.StructTag) {
+		t.Setenv(tag.Get("env"), "")
+	})
+	t.Setenv("CONFIG_FILE", "")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(t.TempDir(), "service")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestLoadDefaultsAndEnvOverrides(t *testing.T) {
+	isolate(t)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	yamlFile := "database:\n  host: db.internal\n  port: 6543\n  user: yaml-user\n  name: fishlink\n"
+	if err := os.WriteFile(path, []byte(yamlFile), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("DATABASE_USER", "env-user")
+	t.Setenv("SHUTDOWN_TIMEOUT", "30s")
+	t.Setenv("TRACING_EXPORTERS", " otlp, ,file ")
+
+	cfg, err := Load(ServiceProduct)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// env beats yaml, yaml beats defaults
+	if cfg.Database.User != "env-user" {
+		t.Errorf("database.user = %q, want env-user", cfg.Database.User)
+	}
+	if cfg.Database.Host != "db.internal" || cfg.Database.Port != 6543 {
+		t.Errorf("database = %s:%d, want db.internal:6543", cfg.Database.Host, cfg.Database.Port)
+	}
+	if cfg.ShutdownTimeout != 30*time.Second {
+		t.Errorf("shutdown_timeout = %s, want 30s", cfg.ShutdownTimeout)
+	}
+	if cfg.HealthCheckTimeout != 2*time.Second || cfg.ProductService.Addr != ":50051" {
+		t.Errorf("defaults not applied: %+v", cfg)
+	}
+	// metrics are not exposed beyond the host unless asked for
+	if cfg.API.MetricsAddr != "127.0.0.1:9091" || cfg.ProductService.MetricsAddr != "127.0.0.1:9090" {
+		t.Errorf("metrics addrs = %s, %s, want loopback", cfg.API.MetricsAddr, cfg.ProductService.MetricsAddr)
+	}
+	if names := cfg.Tracing.ExporterNames(); !reflect.DeepEqual(names, []string{ExporterOTLP, ExporterFile}) {
+		t.Errorf("exporters = %v", names)
+	}
+}
+
+func TestLoadValidationErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		service string
+		env     map[string]string
+		want    []string
+	}{
+		{
+			name:    "missing required keys of the service",
+			service: ServiceAPI,
+			want:    []string{"missing DATABASE_USER", "missing DATABASE_NAME", "missing SECRETSIGN", "missing MIDTRANS_SERVER_KEY"},
+		},
+		{
+			name:    "keys of other services are not required",
+			service: ServiceEmailNotification,
+			env:     map[string]string{"MAIL_AUTH_EMAIL": "a@example.com", "AUTHMAILPASSWORD": "secret"},
+			want:    []string{"missing MAIL_SENDER"},
+		},
+		{
+			name:    "invalid values",
+			service: ServiceEmailNotificationReplay,
+			env: map[string]string{
+				"DATABASE_PORT":        "abc",
+				"MAIL_SMTP_PORT":       "70000",
+				"HEALTH_CHECK_TIMEOUT": "0s",
+				"TRACING_EXPORTERS":    "otlp,jaeger",
+			},
+			want: []string{
+				`DATABASE_PORT (database.port): invalid value "abc"`,
+				"mail.smtp_port: port 70000 out of range",
+				"health_check_timeout: 0s must be positive",
+				`unknown exporter "jaeger"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolate(t)
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			_, err := Load(tt.service)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error does not mention %q:\n%v", want, err)
+				}
+			}
+			if got, want := strings.Count(err.Error(), "\n"), len(tt.want); got != want {
+				t.Errorf("error lists %d problems, want %d:\n%v", got, want, err)
+			}
+		})
+	}
+}
@@ -9,15 +9,15 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func NewMongoDB() *mongo.Database{
+func NewMongoDB(cfg MongoDBConfig) *mongo.Database{
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017/"))
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.URI))
 
 	if err != nil {
 		log.Fatal(err.Error())
 	}
 
-	db := client.Database("review-w2")
+	db := client.Database(cfg.Database)
 	return db
 }
\ No newline at end of file
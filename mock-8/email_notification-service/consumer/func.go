@@ -1,42 +1,171 @@
 package consumer
 
 import (
-	"encoding/json"
-	"final_project-ftgo-h8/api/dto"
+	"context"
+	"errors"
+	"final_project-ftgo-h8/event"
 	"final_project-ftgo-h8/helper"
+	"final_project-ftgo-h8/metrics"
+	"final_project-ftgo-h8/tracing"
 	"fmt"
 	"log"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	errUnknownEvent     = errors.New("unknown event type")
+	errDeliveriesClosed = errors.New("delivery channel closed by the broker")
 )
 
-func (c *registerNotification) ConsumeQueuedMessage(queueName string){
+// ConsumeQueuedMessage handles deliveries until ctx is done, the message being handled is finished first.
+func (c *registerNotification) ConsumeQueuedMessage(ctx context.Context, queueName string) error{
+	// deliver a few messages at a time, each one is acked after it is handled
+	err := c.channel.Qos(10, 0, false)
+	if err != nil {
+		return err
+	}
+
+	consumerTag := queueName + ".consumer"
 	msgs, err := c.channel.Consume(
 		queueName, // queue
-		"",     // consumer
-		true,   // auto-ack
+		consumerTag, // consumer
+		false,  // auto-ack
 		false,  // exclusive
 		false,  // no-local
 		false,  // no-wait
 		nil,    // args
 	)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
+	c.consuming.Store(true)
+	defer c.consuming.Store(false)
 	
-	
-	for d := range msgs {
-	  	log.Printf("Received a message: %s", d.Body)
-		  
-	  	var userVerif dto.UserEmailVerification
-	  	err := json.Unmarshal(d.Body, &userVerif)
-	  	if err != nil {
-				log.Fatal(err)
-	  	}
+	for {
+		select {
+		case <-ctx.Done():
+			// stop new deliveries, prefetched messages are requeued when the channel closes
+			return c.channel.Cancel(consumerTag, false)
+		case d, ok := <-msgs:
+			if !ok {
+				return errDeliveriesClosed
+			}
+			log.Printf("Received a message: %s", describeDelivery(d))
+			c.handleDelivery(queueName, d)
+			c.handled.Add(1)
+			c.lastHandledAt.Store(time.Now().UnixNano())
+		}
+	}
+}
+
+// describeDelivery names a delivery for logs by its event type and id, the payload
+// holds password reset tokens and verification codes and is never logged.
+func describeDelivery(d amqp.Delivery) string {
+	eventType := "unknown"
+	if envelope, err := event.Unmarshal(d.Body); err == nil {
+		eventType = envelope.Type
+	}
+	if d.MessageId != "" {
+		return fmt.Sprintf("type=%s id=%s", eventType, d.MessageId)
+	}
+	return fmt.Sprintf("type=%s delivery=%d", eventType, d.DeliveryTag)
+}
+
+// Status reports whether the consumer is receiving deliveries and how many it has handled.
+func (c *registerNotification) Status() Status {
+	status := Status{Consuming: c.consuming.Load(), Handled: c.handled.Load()}
+	if at := c.lastHandledAt.Load(); at != 0 {
+		lastHandledAt := time.Unix(0, at)
+		status.LastHandledAt = &lastHandledAt
+	}
+	return status
+}
 
-	  	subject := "Fishlink account verification"
-	  	message := fmt.Sprintf("Your verification link : http://localhost:8080/user-verification-register/%d/%s", userVerif.UserId,userVerif.VerificationCode)
+// handleDelivery sends the email of the delivery and acks it. Messages that can not be rendered
+// are dead-lettered right away, failed sends are retried with backoff.
+func (c *registerNotification) handleDelivery(queueName string, d amqp.Delivery) {
+	// continue the trace of the publisher, the message is finished even when the consumer is stopping
+	ctx, span := tracing.Tracer().Start(tracing.ExtractAMQP(context.Background(), d.Headers), queueName+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystem("rabbitmq"),
+			semconv.MessagingDestinationName(queueName),
+			attribute.Int("messaging.rabbitmq.attempt", attemptOf(d)+1),
+		),
+	)
+	defer span.End()
 
-		helper.SendMail(userVerif.Email, subject, message)
+	result := metrics.ConsumeSent
+	mail, err := c.renderMessage(d.Body)
+	if err != nil {
+		log.Printf("Failed to handle message: %v", err)
+		result = metrics.ConsumeDeadLettered
+		span.RecordError(err)
+		err = c.deadLetter(ctx, queueName, d, attemptOf(d)+1, err)
+	} else if err = helper.SendMailHTML(c.mail, mail.to, mail.subject, mail.text, mail.html); err != nil {
+		log.Printf("Failed to send mail to %s: %v", mail.to, err)
+		result = metrics.ConsumeRetried
+		if attemptOf(d)+1 >= MaxAttempts {
+			result = metrics.ConsumeDeadLettered
+		}
+		span.RecordError(err)
+		err = c.retry(ctx, queueName, d, err)
+	} else {
+		metrics.EmailsSent.WithLabelValues(mail.eventType).Inc()
 	}
-	
-	fmt.Println("TESSSSSS")
+	span.SetAttributes(attribute.String("messaging.rabbitmq.result", result))
+	if result != metrics.ConsumeSent {
+		span.SetStatus(codes.Error, result)
+	}
+
+	// keep the message in the queue when it could not be moved to a retry or dead-letter queue
+	if err != nil {
+		log.Printf("Failed to requeue message: %v", err)
+		metrics.RabbitMQConsumed.WithLabelValues(queueName, metrics.ConsumeRequeued).Inc()
+		d.Nack(false, true)
+		return
+	}
+	metrics.RabbitMQConsumed.WithLabelValues(queueName, result).Inc()
+
+	err = d.Ack(false)
+	if err != nil {
+		log.Printf("Failed to ack message: %v", err)
+	}
+}
+
+// renderMessage reads the event envelope and renders the email of its handler
+func (c *registerNotification) renderMessage(body []byte) (email, error) {
+	envelope, err := event.Unmarshal(body)
+	if err != nil {
+		return email{}, err
+	}
+
+	h, ok := handlers[envelope.Type]
+	if !ok {
+		return email{}, fmt.Errorf("%w: %s", errUnknownEvent, envelope.Type)
+	}
+	if envelope.Version < 1 || envelope.Version > h.maxVersion {
+		return email{}, fmt.Errorf("unsupported %s schema version %d", envelope.Type, envelope.Version)
+	}
+
+	to, payload, err := h.decode(envelope.Payload)
+	if err != nil {
+		return email{}, err
+	}
+
+	subject, text, html, err := c.templates[envelope.Type].render(struct {
+		BaseURL string
+		Payload interface{}
+	}{c.baseURL, payload})
+	if err != nil {
+		return email{}, err
+	}
+
+	return email{eventType: envelope.Type, to: to, subject: subject, text: text, html: html}, nil
 }
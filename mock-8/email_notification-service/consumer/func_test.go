@@ -0,0 +1,38 @@
+package consumer
+
+import (
+	"final_project-ftgo-h8/event"
+	"strings"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestDescribeDeliveryOmitsPayload(t *testing.T) {
+	body, err := event.Marshal(event.TypePasswordReset, event.PasswordReset{
+		Email:      "buyer@example.com",
+		ResetToken: "secret-reset-token",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		delivery amqp.Delivery
+		want     string
+	}{
+		{amqp.Delivery{Body: body, MessageId: "abc123"}, "type=user.password_reset id=abc123"},
+		{amqp.Delivery{Body: body, DeliveryTag: 7}, "type=user.password_reset delivery=7"},
+		{amqp.Delivery{Body: []byte("secret-reset-token"), DeliveryTag: 8}, "type=unknown delivery=8"},
+	}
+
+	for _, tt := range tests {
+		got := describeDelivery(tt.delivery)
+		if got != tt.want {
+			t.Errorf("describeDelivery() = %q, want %q", got, tt.want)
+		}
+		if strings.Contains(got, "secret-reset-token") || strings.Contains(got, "buyer@example.com") {
+			t.Errorf("describeDelivery() leaks the payload: %q", got)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package consumer
+
+import (
+	"encoding/json"
+	"final_project-ftgo-h8/event"
+)
+
+type email struct {
+	eventType string
+	to        string
+	subject   string
+	text      string
+	html      string
+}
+
+// handler turns the payload of one event type into an email
+type handler struct {
+	// highest payload schema version the handler understands
+	maxVersion int
+	// decode the payload and return the recipient and template data
+	decode func(payload json.RawMessage) (string, interface{}, error)
+}
+
+func decodeTo[T any](recipient func(T) string) func(json.RawMessage) (string, interface{}, error) {
+	return func(payload json.RawMessage) (string, interface{}, error) {
+		var v T
+		if err := json.Unmarshal(payload, &v); err != nil {
+			return "", nil, err
+		}
+		return recipient(v), v, nil
+	}
+}
+
+var handlers = map[string]handler{
+	event.TypeUserVerification: {
+		maxVersion: 1,
+		decode:     decodeTo(func(v event.UserVerification) string { return v.Email }),
+	},
+	event.TypePasswordReset: {
+		maxVersion: 1,
+		decode:     decodeTo(func(v event.PasswordReset) string { return v.Email }),
+	},
+	event.TypeOrderConfirmation: {
+		maxVersion: 1,
+		decode:     decodeTo(func(v event.OrderConfirmation) string { return v.Email }),
+	},
+	event.TypeShipmentUpdate: {
+		maxVersion: 1,
+		decode:     decodeTo(func(v event.ShipmentUpdate) string { return v.Email }),
+	},
+	event.TypeTopUpSuccess: {
+		maxVersion: 1,
+		decode:     decodeTo(func(v event.TopUpSuccess) string { return v.Email }),
+	},
+	event.TypeLowStock: {
+		maxVersion: 1,
+		decode:     decodeTo(func(v event.LowStock) string { return v.Email }),
+	},
+	event.TypeBackInStock: {
+		maxVersion: 1,
+		decode:     decodeTo(func(v event.BackInStock) string { return v.Email }),
+	},
+}
+
+// EventTypes lists every event type the consumer has a handler for.
+func EventTypes() []string {
+	eventTypes := make([]string, 0, len(handlers))
+	for eventType := range handlers {
+		eventTypes = append(eventTypes, eventType)
+	}
+	return eventTypes
+}
@@ -1,18 +1,41 @@
 package consumer
 
 import (
+	"context"
+	"final_project-ftgo-h8/config"
+	"sync/atomic"
+	"time"
+
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
 type Consumer interface {
-	ConsumeQueuedMessage(queueName string)
+	ConsumeQueuedMessage(ctx context.Context, queueName string) error
+	Status() Status
+}
+
+// Status is the state of the consumer reported by the health endpoint.
+type Status struct {
+	Consuming     bool       `json:"consuming"`
+	Handled       uint64     `json:"handled"`
+	LastHandledAt *time.Time `json:"last_handled_at,omitempty"`
 }
 type registerNotification struct {
 	channel *amqp.Channel
+	templates Templates
+	baseURL string
+	mail config.MailConfig
+
+	consuming     atomic.Bool
+	handled       atomic.Uint64
+	lastHandledAt atomic.Int64
 }
 
-func NewRegisterNotification(c *amqp.Channel) Consumer{
+func NewRegisterNotification(c *amqp.Channel, t Templates, baseURL string, mail config.MailConfig) Consumer{
 	return &registerNotification{
 		channel: c,
+		templates: t,
+		baseURL: baseURL,
+		mail: mail,
 	}
 }
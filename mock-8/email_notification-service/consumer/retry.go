@@ -0,0 +1,122 @@
+package consumer
+
+import (
+	"context"
+	"final_project-ftgo-h8/metrics"
+	"final_project-ftgo-h8/tracing"
+	"fmt"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	// number of delivery attempts before a message is moved to the dead-letter queue
+	MaxAttempts = 5
+	// delay before the first retry, doubled on every next attempt
+	retryBaseDelay = 10 * time.Second
+
+	headerAttempt = "x-attempt"
+	headerError   = "x-error"
+)
+
+// DeadLetterQueueName returns the queue that keeps messages which failed every attempt.
+func DeadLetterQueueName(queueName string) string {
+	return queueName + ".dlq"
+}
+
+// RetryQueueName returns the delay queue used before the given attempt.
+func RetryQueueName(queueName string, attempt int) string {
+	return fmt.Sprintf("%s.retry.%d", queueName, attempt)
+}
+
+// RetryDelay returns the exponential backoff waited before the given attempt.
+func RetryDelay(attempt int) time.Duration {
+	return retryBaseDelay << (attempt - 1)
+}
+
+// attemptOf returns how many times the delivery has already failed
+func attemptOf(d amqp.Delivery) int {
+	switch v := d.Headers[headerAttempt].(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
+
+// republish copies the delivery to queueName with the given headers and the trace context of ctx
+// as a persistent message
+func (c *registerNotification) republish(ctx context.Context, queueName string, d amqp.Delivery, headers amqp.Table) error {
+	err := c.channel.PublishWithContext(ctx,
+		"",        // exchange
+		queueName, // routing key
+		false,     // mandatory
+		false,     // immediate
+		amqp.Publishing{
+			ContentType:  d.ContentType,
+			MessageId:    d.MessageId,
+			DeliveryMode: amqp.Persistent,
+			Headers:      tracing.InjectAMQP(ctx, headers),
+			Body:         d.Body,
+		})
+	metrics.ObservePublish(queueName, err)
+	return err
+}
+
+// retry sends the delivery to the delay queue of its next attempt, or to the dead-letter queue
+// once MaxAttempts is reached
+func (c *registerNotification) retry(ctx context.Context, queueName string, d amqp.Delivery, cause error) error {
+	attempt := attemptOf(d) + 1
+	if attempt >= MaxAttempts {
+		return c.deadLetter(ctx, queueName, d, attempt, cause)
+	}
+
+	return c.republish(ctx, RetryQueueName(queueName, attempt), d, amqp.Table{
+		headerAttempt: int32(attempt),
+		headerError:   cause.Error(),
+	})
+}
+
+// deadLetter moves a message that can not be handled to the <queue>.dlq queue
+func (c *registerNotification) deadLetter(ctx context.Context, queueName string, d amqp.Delivery, attempt int, cause error) error {
+	return c.republish(ctx, DeadLetterQueueName(queueName), d, amqp.Table{
+		headerAttempt: int32(attempt),
+		headerError:   cause.Error(),
+	})
+}
+
+// ReplayDeadLetters moves up to limit messages from the dead-letter queue back to queueName
+// with a fresh attempt count. A limit of 0 replays every message.
+func ReplayDeadLetters(ch *amqp.Channel, queueName string, limit int) (int, error) {
+	c := &registerNotification{channel: ch}
+
+	replayed := 0
+	for limit == 0 || replayed < limit {
+		d, ok, err := ch.Get(DeadLetterQueueName(queueName), false)
+		if err != nil {
+			return replayed, err
+		}
+		if !ok {
+			break
+		}
+
+		// the replayed message stays in the trace it was published in
+		err = c.republish(tracing.ExtractAMQP(context.Background(), d.Headers), queueName, d, nil)
+		if err != nil {
+			d.Nack(false, true)
+			return replayed, err
+		}
+
+		err = d.Ack(false)
+		if err != nil {
+			return replayed, err
+		}
+		replayed++
+	}
+
+	return replayed, nil
+}
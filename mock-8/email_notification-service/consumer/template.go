@@ -0,0 +1,51 @@
+package consumer
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"path/filepath"
+	texttemplate "text/template"
+)
+
+// email templates of one event type, loaded from <type>.txt.tmpl and <type>.html.tmpl
+type emailTemplate struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+type Templates map[string]emailTemplate
+
+func LoadTemplates(dir string, eventTypes []string) (Templates, error) {
+	templates := Templates{}
+	for _, eventType := range eventTypes {
+		text, err := texttemplate.ParseFiles(filepath.Join(dir, eventType+".txt.tmpl"))
+		if err != nil {
+			return nil, err
+		}
+
+		html, err := htmltemplate.ParseFiles(filepath.Join(dir, eventType+".html.tmpl"))
+		if err != nil {
+			return nil, err
+		}
+
+		templates[eventType] = emailTemplate{text: text, html: html}
+	}
+
+	return templates, nil
+}
+
+// render returns the subject, text and html body of the email
+func (t emailTemplate) render(data interface{}) (string, string, string, error) {
+	var subject, text, html bytes.Buffer
+	if err := t.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return "", "", "", err
+	}
+	if err := t.text.ExecuteTemplate(&text, "text", data); err != nil {
+		return "", "", "", err
+	}
+	if err := t.html.ExecuteTemplate(&html, "html", data); err != nil {
+		return "", "", "", err
+	}
+
+	return subject.String(), text.String(), html.String(), nil
+}
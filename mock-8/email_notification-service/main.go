@@ -1,32 +1,94 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"final_project-ftgo-h8/config"
 	"final_project-ftgo-h8/email_notification-service/consumer"
-	"final_project-ftgo-h8/helper"
+	"final_project-ftgo-h8/event"
+	"final_project-ftgo-h8/health"
+	"final_project-ftgo-h8/lifecycle"
+	"final_project-ftgo-h8/metrics"
+	"final_project-ftgo-h8/tracing"
 	"log"
-
-	_ "github.com/joho/godotenv/autoload"
+	"net/http"
 )
 
 func main(){
-	// load env
-	helper.LoadEnv()
+	// load config
+	cfg := config.MustLoad(config.ServiceEmailNotification)
+
+	// components are stopped in reverse order on shutdown
+	app := lifecycle.New(cfg.ShutdownTimeout)
+
+	// init tracing, added first so spans are flushed after everything else stopped
+	shutdownTracing, err := tracing.Setup(config.ServiceEmailNotification, cfg.Tracing)
+	if err != nil {
+		log.Fatal(err)
+	}
+	app.Add(lifecycle.Component{Name: "tracing", Stop: shutdownTracing})
 
 	// init channel
-	channel := config.NewChannel()
+	conn := config.NewConnection(cfg.RabbitMQ)
+	app.Add(lifecycle.Closer("rabbitmq", conn.Close))
+	channel := config.NewChannel(conn)
 	
 	// init queue
-	registerQueue := config.AddQueue(channel,"fishlink-email_notification")
+	registerQueue := config.AddQueue(channel,event.EmailNotificationQueue)
+	deadLetterQueue := config.AddQueue(channel,consumer.DeadLetterQueueName(registerQueue.Name))
+	queueNames := []string{registerQueue.Name, deadLetterQueue.Name}
+	for attempt := 1; attempt < consumer.MaxAttempts; attempt++ {
+		retryQueue := config.AddDelayQueue(channel,consumer.RetryQueueName(registerQueue.Name,attempt),registerQueue.Name,consumer.RetryDelay(attempt))
+		queueNames = append(queueNames, retryQueue.Name)
+	}
+	metrics.RegisterQueueDepth(conn, queueNames...)
+
+	// init email templates
+	templates,err := consumer.LoadTemplates(cfg.EmailNotification.TemplateDir,consumer.EventTypes())
+	if err != nil {
+		log.Fatal(err)
+	}
 	
 	// init consumer
-	registerQonsumer := consumer.NewRegisterNotification(channel)
+	registerQonsumer := consumer.NewRegisterNotification(channel,templates,cfg.EmailNotification.AppBaseURL,cfg.Mail)
 	
+	// serve health and metrics endpoints, ready while rabbitmq is connected and the consumer receives deliveries
+	checker := health.NewChecker(cfg.HealthCheckTimeout)
+	checker.Add("rabbitmq", health.RabbitMQ(conn, channel))
+	checker.Add("consumer", func(ctx context.Context) error {
+		if !registerQonsumer.Status().Consuming {
+			return errors.New("not consuming")
+		}
+		return nil
+	})
+	checker.AddInfo("consumer", func() interface{} { return registerQonsumer.Status() })
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", health.LivenessHandler())
+	mux.Handle("/readyz", checker.ReadinessHandler())
+	mux.Handle("/metrics", metrics.Handler())
+	healthServer := &http.Server{Addr: cfg.EmailNotification.HealthAddr, Handler: mux}
+	app.Add(lifecycle.Component{
+		Name: "health server",
+		Start: func(ctx context.Context) error {
+			if err := healthServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+		Stop: healthServer.Shutdown,
+	})
+
 	// start register consume
-	go registerQonsumer.ConsumeQueuedMessage(registerQueue.Name)
+	app.Add(lifecycle.Component{
+		Name: "email notification consumer",
+		Start: func(ctx context.Context) error {
+			return registerQonsumer.ConsumeQueuedMessage(ctx, registerQueue.Name)
+		},
+	})
 	
-	// continue
-	var forever chan struct{}
+	// run until SIGINT or SIGTERM
 	log.Printf(" [*] Waiting for messages. To exit press CTRL+C")
-	<-forever
+	if err := app.Run(); err != nil {
+		log.Fatal(err)
+	}
 }
\ No newline at end of file
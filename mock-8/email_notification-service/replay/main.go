@@ -0,0 +1,31 @@
+package main
+
+import (
+	"final_project-ftgo-h8/config"
+	"final_project-ftgo-h8/email_notification-service/consumer"
+	"final_project-ftgo-h8/event"
+	"flag"
+	"log"
+)
+
+// replay moves dead-lettered email notifications back to the email notification queue
+func main(){
+	queueName := flag.String("queue", event.EmailNotificationQueue, "queue whose dead-letter queue is replayed")
+	limit := flag.Int("limit", 0, "max number of messages to replay, 0 replays all")
+	flag.Parse()
+
+	// load config
+	cfg := config.MustLoad(config.ServiceEmailNotificationReplay)
+
+	// init channel
+	conn := config.NewConnection(cfg.RabbitMQ)
+	defer conn.Close()
+	channel := config.NewChannel(conn)
+
+	// replay
+	replayed,err := consumer.ReplayDeadLetters(channel,*queueName,*limit)
+	if err != nil {
+		log.Fatalf("replayed %d messages before error: %v", replayed, err)
+	}
+	log.Printf("replayed %d messages from %s", replayed, consumer.DeadLetterQueueName(*queueName))
+}
@@ -0,0 +1,76 @@
+package event
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EmailNotificationQueue is the queue every event below is published to and consumed from.
+const EmailNotificationQueue = "fishlink-email_notification"
+
+// event types published to the email notification queue
+const (
+	TypeUserVerification  = "user.verification"
+	TypePasswordReset     = "user.password_reset"
+	TypeOrderConfirmation = "order.confirmation"
+	TypeShipmentUpdate    = "order.shipment_update"
+	TypeTopUpSuccess      = "wallet.top_up_success"
+	TypeLowStock          = "product.low_stock"
+	TypeBackInStock       = "product.back_in_stock"
+)
+
+// ProductServiceQueue holds outbox messages the api gateway delivers to product-service over gRPC,
+// it is not a rabbitmq queue.
+const ProductServiceQueue = "fishlink-product_service"
+
+// event types delivered to product-service
+const (
+	TypeStockCancel = "stock.cancel"
+)
+
+// current schema version of every payload
+const SchemaVersion = 1
+
+type Envelope struct {
+	Type       string          `json:"type"`
+	Version    int             `json:"version"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+func Marshal(eventType string, payload interface{}) ([]byte, error) {
+	payloadByte, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(Envelope{
+		Type:       eventType,
+		Version:    SchemaVersion,
+		OccurredAt: time.Now(),
+		Payload:    payloadByte,
+	})
+}
+
+// Unmarshal reads an envelope. Messages published before the envelope existed
+// carry the payload at the top level and are read as version 1.
+func Unmarshal(body []byte) (Envelope, error) {
+	var envelope Envelope
+	err := json.Unmarshal(body, &envelope)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	if envelope.Payload == nil {
+		switch envelope.Type {
+		case "":
+			envelope.Type = TypeUserVerification
+		case "password_reset":
+			envelope.Type = TypePasswordReset
+		}
+		envelope.Version = 1
+		envelope.Payload = body
+	}
+
+	return envelope, nil
+}
@@ -0,0 +1,72 @@
+package event
+
+import "final_project-ftgo-h8/quantity"
+
+type UserVerification struct {
+	Email            string `json:"email"`
+	UserId           uint   `json:"user_id"`
+	VerificationCode string `json:"verification_code"`
+}
+
+type PasswordReset struct {
+	Email      string `json:"email"`
+	ResetToken string `json:"reset_token"`
+	ExpiresAt  string `json:"expires_at"`
+}
+
+type OrderItem struct {
+	ProductName string            `json:"product_name"`
+	Quantity    quantity.Quantity `json:"quantity"`
+	Unit        string            `json:"unit,omitempty"`
+	TotalPrice  int64             `json:"total_price"`
+}
+
+type OrderConfirmation struct {
+	Email      string      `json:"email"`
+	Name       string      `json:"name"`
+	OrderId    uint        `json:"order_id"`
+	Items      []OrderItem `json:"items"`
+	TotalPrice int64       `json:"total_price"`
+	OrderedAt  string      `json:"ordered_at"`
+}
+
+type ShipmentUpdate struct {
+	Email   string `json:"email"`
+	Name    string `json:"name"`
+	OrderId uint   `json:"order_id"`
+	Status  string `json:"status"`
+}
+
+type TopUpSuccess struct {
+	Email   string `json:"email"`
+	Name    string `json:"name"`
+	TopUpId string `json:"top_up_id"`
+	Amount  int64  `json:"amount"`
+	Balance int64  `json:"balance"`
+}
+
+// LowStock goes to the seller of the product, or to every admin for marketplace products
+type LowStock struct {
+	Email       string            `json:"email"`
+	Name        string            `json:"name"`
+	ProductId   uint              `json:"product_id"`
+	ProductName string            `json:"product_name"`
+	Stock       quantity.Quantity `json:"stock"`
+	Threshold   quantity.Quantity `json:"threshold"`
+	Unit        string            `json:"unit"`
+}
+
+// BackInStock goes to every buyer subscribed to the restock of the product
+type BackInStock struct {
+	Email       string            `json:"email"`
+	Name        string            `json:"name"`
+	ProductId   uint              `json:"product_id"`
+	ProductName string            `json:"product_name"`
+	Stock       quantity.Quantity `json:"stock"`
+	Unit        string            `json:"unit"`
+}
+
+// StockCancel returns the stock of a cancelled order once the cancellation is committed
+type StockCancel struct {
+	ReservationId string `json:"reservation_id"`
+}
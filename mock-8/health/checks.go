@@ -0,0 +1,81 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// SQL pings the database.
+func SQL(db *sql.DB) Check {
+	return db.PingContext
+}
+
+// MongoDB pings the primary of the mongodb deployment.
+func MongoDB(client *mongo.Client) Check {
+	return func(ctx context.Context) error {
+		return client.Ping(ctx, readpref.Primary())
+	}
+}
+
+// RabbitMQ fails once the connection or the channel has been closed.
+func RabbitMQ(conn *amqp.Connection, ch *amqp.Channel) Check {
+	return func(ctx context.Context) error {
+		if conn.IsClosed() {
+			return errors.New("connection closed")
+		}
+		if ch.IsClosed() {
+			return errors.New("channel closed")
+		}
+		return nil
+	}
+}
+
+// GRPC asks the grpc.health.v1 service behind conn whether service is serving.
+func GRPC(conn *grpc.ClientConn, service string) Check {
+	client := healthpb.NewHealthClient(conn)
+	return func(ctx context.Context) error {
+		res, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			return err
+		}
+		if res.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+			return fmt.Errorf("%s is %s", service, res.GetStatus())
+		}
+		return nil
+	}
+}
+
+// ServeGRPC keeps the grpc.health.v1 status of services in sync with the checks every interval,
+// every service is reported not serving once ctx is done.
+func (c *Checker) ServeGRPC(ctx context.Context, server *grpchealth.Server, interval time.Duration, services ...string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		status := healthpb.HealthCheckResponse_SERVING
+		if c.Run(ctx).Status != StatusOK {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		server.SetServingStatus("", status)
+		for _, service := range services {
+			server.SetServingStatus(service, status)
+		}
+
+		select {
+		case <-ctx.Done():
+			server.Shutdown()
+			return
+		case <-ticker.C:
+		}
+	}
+}
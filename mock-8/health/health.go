@@ -0,0 +1,146 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// status of a service or one of its checks
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+)
+
+// Check returns an error when a dependency can not be used.
+type Check func(ctx context.Context) error
+
+// CheckResult is the outcome of one check.
+type CheckResult struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Report is the response of the readiness endpoint.
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+	Info   map[string]interface{} `json:"info,omitempty"`
+}
+
+// Checker runs the dependency checks of a service, each one with its own timeout.
+type Checker struct {
+	timeout time.Duration
+	names   []string
+	checks  map[string]Check
+	info    map[string]func() interface{}
+}
+
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{
+		timeout: timeout,
+		checks:  map[string]Check{},
+		info:    map[string]func() interface{}{},
+	}
+}
+
+// Add registers a check that makes the service unready when it fails.
+func (c *Checker) Add(name string, check Check) {
+	c.names = append(c.names, name)
+	c.checks[name] = check
+}
+
+// AddInfo registers extra state shown in the report without affecting readiness.
+func (c *Checker) AddInfo(name string, info func() interface{}) {
+	c.info[name] = info
+}
+
+// Run runs every check concurrently and reports the service ready when all of them pass.
+func (c *Checker) Run(ctx context.Context) Report {
+	report := Report{Status: StatusOK, Checks: map[string]CheckResult{}}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, name := range c.names {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+			result := c.run(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			if result.Status != StatusOK {
+				report.Status = StatusUnavailable
+			}
+		}(name, c.checks[name])
+	}
+	wg.Wait()
+
+	if len(c.info) > 0 {
+		report.Info = map[string]interface{}{}
+		for name, info := range c.info {
+			report.Info[name] = info()
+		}
+	}
+
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := make(chan error, 1)
+	go func() { err <- check(ctx) }()
+
+	// a check ignoring its ctx still fails after the timeout
+	var checkErr error
+	select {
+	case checkErr = <-err:
+	case <-ctx.Done():
+		checkErr = ctx.Err()
+	}
+	if errors.Is(checkErr, context.DeadlineExceeded) {
+		checkErr = errors.New("timed out")
+	}
+
+	result := CheckResult{Status: StatusOK, DurationMs: time.Since(start).Milliseconds()}
+	if checkErr != nil {
+		result.Status = StatusUnavailable
+		result.Error = checkErr.Error()
+	}
+	return result
+}
+
+// LivenessHandler answers 200 while the process can serve http, dependencies are not checked.
+func LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, http.StatusOK, Report{Status: StatusOK})
+	})
+}
+
+// ReadinessHandler answers 200 when every check passes and 503 otherwise.
+func (c *Checker) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := c.Run(r.Context())
+
+		code := http.StatusOK
+		if report.Status != StatusOK {
+			code = http.StatusServiceUnavailable
+		}
+		writeReport(w, code, report)
+	})
+}
+
+func writeReport(w http.ResponseWriter, code int, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,73 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestReadinessHandler(t *testing.T) {
+	ok := func(ctx context.Context) error { return nil }
+	down := func(ctx context.Context) error { return errors.New("connection refused") }
+	hang := func(ctx context.Context) error { time.Sleep(time.Second); return nil }
+
+	tests := []struct {
+		name   string
+		checks map[string]Check
+		code   int
+		failed map[string]string
+	}{
+		{"all pass", map[string]Check{"postgres": ok, "rabbitmq": ok}, http.StatusOK, nil},
+		{"one dependency down", map[string]Check{"postgres": ok, "rabbitmq": down}, http.StatusServiceUnavailable, map[string]string{"rabbitmq": "connection refused"}},
+		{"check ignoring its timeout", map[string]Check{"postgres": ok, "mongodb": hang}, http.StatusServiceUnavailable, map[string]string{"mongodb": "timed out"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(50 * time.Millisecond)
+			for name, check := range tt.checks {
+				checker.Add(name, check)
+			}
+			checker.AddInfo("outbox_pending", func() interface{} { return 3 })
+
+			rec := httptest.NewRecorder()
+			checker.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if rec.Code != tt.code {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.code)
+			}
+
+			var report Report
+			if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+				t.Fatal(err)
+			}
+			if len(report.Checks) != len(tt.checks) {
+				t.Errorf("checks = %v, want %d results", report.Checks, len(tt.checks))
+			}
+			for name, result := range report.Checks {
+				if want, failed := tt.failed[name]; failed {
+					if result.Status != StatusUnavailable || result.Error != want {
+						t.Errorf("%s = %+v, want unavailable: %s", name, result, want)
+					}
+				} else if result.Status != StatusOK {
+					t.Errorf("%s = %+v, want ok", name, result)
+				}
+			}
+			// info never changes readiness
+			if report.Info["outbox_pending"] != float64(3) {
+				t.Errorf("info = %v", report.Info)
+			}
+		})
+	}
+}
+
+func TestLivenessHandlerIgnoresDependencies(t *testing.T) {
+	rec := httptest.NewRecorder()
+	LivenessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
@@ -1,15 +0,0 @@
-package helper
-
-import (
-	"log"
-
-	"github.com/joho/godotenv"
-)
-
-func LoadEnv() {
-	err := godotenv.Load("../.env")
-
-	if err != nil {
-		log.Fatal("Error loading .env file")
-	}
-}
\ No newline at end of file
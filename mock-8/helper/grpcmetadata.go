@@ -0,0 +1,61 @@
+package helper
+
+import (
+	"context"
+	"strconv"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// gRPC metadata keys set by the api gateway for the authenticated user
+const (
+	MetadataUserId   = "x-user-id"
+	MetadataUserRole = "x-user-role"
+)
+
+func AppendUserMetadata(ctx context.Context, userId uint, role string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx,
+		MetadataUserId, strconv.FormatUint(uint64(userId), 10),
+		MetadataUserRole, role,
+	)
+}
+
+// AppendGatewayMetadata marks calls the api gateway makes on its own behalf, e.g. returning
+// the stock of a cancelled order, they need admin rights.
+func AppendGatewayMetadata(ctx context.Context) context.Context {
+	return AppendUserMetadata(ctx, 0, "Admin")
+}
+
+func UserRoleFromMetadata(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get(MetadataUserRole)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+// UserIdFromMetadata returns the user id set by the api gateway, 0 when there is none
+func UserIdFromMetadata(ctx context.Context) uint {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return 0
+	}
+
+	values := md.Get(MetadataUserId)
+	if len(values) == 0 {
+		return 0
+	}
+
+	userId, err := strconv.ParseUint(values[0], 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return uint(userId)
+}
@@ -1,11 +1,21 @@
 package helper
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// token lifetime
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 7 * 24 * time.Hour
+)
+
 func GenerateJWT(mapClaims jwt.MapClaims, secretSign []byte) (string,error){
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, mapClaims)
 
@@ -18,20 +28,58 @@ func GenerateJWT(mapClaims jwt.MapClaims, secretSign []byte) (string,error){
 	return tokenString,nil
 }
 
+// GenerateAccessToken signs a short lived token for the user with exp, iat and jti claims.
+func GenerateAccessToken(userId uint, secretSign []byte) (string, error) {
+	jti, err := GenerateSecureToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	return GenerateJWT(jwt.MapClaims{
+		"user_id": userId,
+		"iat":     now.Unix(),
+		"exp":     now.Add(AccessTokenTTL).Unix(),
+		"jti":     jti,
+	}, secretSign)
+}
+
 func ParseJWT(tokenString string, secretSign []byte) (jwt.MapClaims,error){
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		// hmacSampleSecret is a []byte containing your secret, e.g. []byte("my_secret_key")
 
 		return secretSign, nil
-	})
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
 	if err != nil {
 		return jwt.MapClaims{}, err
 	}
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+		// tokens issued before expiry was introduced never expire, reject them
+		exp, err := claims.GetExpirationTime()
+		if err != nil || exp == nil {
+			return jwt.MapClaims{}, errors.New("token has no expiration")
+		}
+
 		return claims,nil
 	}
 
 	return jwt.MapClaims{},errors.New("invalid token")
-}
\ No newline at end of file
+}
+
+// GenerateSecureToken returns a random hex string of n bytes for tokens that must not be guessed.
+func GenerateSecureToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// HashToken returns the sha256 of a token so only the hash is stored server-side.
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
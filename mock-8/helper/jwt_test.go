@@ -0,0 +1,72 @@
+package helper
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestParseJWT(t *testing.T) {
+	secret := []byte("secret")
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := func(exp time.Duration) jwt.MapClaims {
+		return jwt.MapClaims{"user_id": 1, "exp": time.Now().Add(exp).Unix()}
+	}
+	sign := func(method jwt.SigningMethod, claims jwt.MapClaims, key interface{}) string {
+		token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"valid", sign(jwt.SigningMethodHS256, claims(time.Minute), secret), false},
+		{"alg none", sign(jwt.SigningMethodNone, claims(time.Minute), jwt.UnsafeAllowNoneSignatureType), true},
+		{"rs256", sign(jwt.SigningMethodRS256, claims(time.Minute), rsaKey), true},
+		{"hs512 with the same secret", sign(jwt.SigningMethodHS512, claims(time.Minute), secret), true},
+		{"wrong secret", sign(jwt.SigningMethodHS256, claims(time.Minute), []byte("other")), true},
+		{"expired", sign(jwt.SigningMethodHS256, claims(-time.Minute), secret), true},
+		{"no expiry", sign(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": 1}, secret), true},
+		{"garbage", "not.a.token", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := ParseJWT(tt.token, secret)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && claims["user_id"] != float64(1) {
+				t.Errorf("user_id = %v, want 1", claims["user_id"])
+			}
+		})
+	}
+}
+
+func TestGenerateAccessTokenRoundTrip(t *testing.T) {
+	secret := []byte("secret")
+	token, err := GenerateAccessToken(7, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := ParseJWT(token, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims["user_id"] != float64(7) || claims["jti"] == "" {
+		t.Errorf("claims = %v", claims)
+	}
+}
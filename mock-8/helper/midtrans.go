@@ -0,0 +1,19 @@
+package helper
+
+import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+)
+
+// signature key = SHA512(order_id + status_code + gross_amount + server_key)
+func VerifyMidtransSignature(orderId, statusCode, grossAmount, serverKey, signatureKey string) bool {
+	if serverKey == "" {
+		return false
+	}
+
+	hash := sha512.Sum512([]byte(orderId + statusCode + grossAmount + serverKey))
+	expected := hex.EncodeToString(hash[:])
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(signatureKey)) == 1
+}
@@ -0,0 +1,41 @@
+package helper
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"testing"
+)
+
+func TestVerifyMidtransSignature(t *testing.T) {
+	const serverKey = "SB-Mid-server-test"
+	valid := sha512Hex("TOPUP-1" + "200" + "50000.00" + serverKey)
+
+	tests := []struct {
+		name        string
+		grossAmount string
+		serverKey   string
+		signature   string
+		want        bool
+	}{
+		{"valid", "50000.00", serverKey, valid, true},
+		{"missing signature", "50000.00", serverKey, "", false},
+		{"wrong signature", "50000.00", serverKey, valid[:len(valid)-1] + "0", false},
+		{"tampered amount", "90000.00", serverKey, valid, false},
+		{"other server key", "50000.00", "SB-Mid-server-other", valid, false},
+		// an unset key must not accept the hash of the fields alone
+		{"no server key", "50000.00", "", sha512Hex("TOPUP-1" + "200" + "50000.00"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyMidtransSignature("TOPUP-1", "200", tt.grossAmount, tt.serverKey, tt.signature); got != tt.want {
+				t.Errorf("VerifyMidtransSignature = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func sha512Hex(s string) string {
+	hash := sha512.Sum512([]byte(s))
+	return hex.EncodeToString(hash[:])
+}
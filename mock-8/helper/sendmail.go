@@ -1,38 +1,63 @@
 package helper
 
 import (
+	"errors"
+	"final_project-ftgo-h8/config"
 	"fmt"
 	"log"
+	"mime"
+	"mime/multipart"
 	"net/smtp"
-	"os"
+	"net/textproto"
 	"strings"
 )
 
-const CONFIG_SMTP_HOST = "smtp.gmail.com"
-const CONFIG_SMTP_PORT = 587
-const CONFIG_SENDER_NAME = "FishLink <ddummymail65@gmail.com>"
-const CONFIG_AUTH_EMAIL = "ddummymail65@gmail.com"
+var errHeaderInjection = errors.New("mail header contains a line break")
+
+// subjectHeader folds a rendered subject to one line and encodes it, so it can not add headers.
+func subjectHeader(subject string) string {
+	return mime.QEncoding.Encode("utf-8", strings.Join(strings.Fields(subject), " "))
+}
+
+// SendMailHTML sends a multipart/alternative mail with a text and an html part.
+func SendMailHTML(cfg config.MailConfig, mail, subject, textMessage, htmlMessage string) error {
+	if strings.ContainsAny(mail, "\r\n") {
+		return errHeaderInjection
+	}
 
-func SendMail(mail, subject, message string) error {
-	// auth password
-	var CONFIG_AUTH_PASSWORD = os.Getenv("AUTHMAILPASSWORD")
 	// body
-	to := []string{mail}
-	cc := []string{}
-	body := "From: " + CONFIG_SENDER_NAME + "\n" +
-		"To: " + strings.Join(to, ",") + "\n" +
-		"Cc: " + strings.Join(cc, ",") + "\n" +
-		"Subject: " + subject + "\n\n" +
-		message
-
-	auth := smtp.PlainAuth("", CONFIG_AUTH_EMAIL, CONFIG_AUTH_PASSWORD, CONFIG_SMTP_HOST)
-	smtpAddr := fmt.Sprintf("%s:%d", CONFIG_SMTP_HOST, CONFIG_SMTP_PORT)
-
-	err := smtp.SendMail(smtpAddr, auth, CONFIG_AUTH_EMAIL, append(to, cc...), []byte(body))
+	var body strings.Builder
+	writer := multipart.NewWriter(&body)
+	header := "From: " + cfg.Sender + "\r\n" +
+		"To: " + mail + "\r\n" +
+		"Subject: " + subjectHeader(subject) + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/alternative; boundary=" + writer.Boundary() + "\r\n\r\n"
+
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=UTF-8", textMessage},
+		{"text/html; charset=UTF-8", htmlMessage},
+	} {
+		w, err := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {part.contentType}})
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write([]byte(part.content)); err != nil {
+			return err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	auth := smtp.PlainAuth("", cfg.AuthEmail, cfg.AuthPassword, cfg.SMTPHost)
+	smtpAddr := fmt.Sprintf("%s:%d", cfg.SMTPHost, cfg.SMTPPort)
+
+	err := smtp.SendMail(smtpAddr, auth, cfg.AuthEmail, []string{mail}, []byte(header+body.String()))
 	if err != nil {
 		return err
 	}
 	log.Printf("success send mail to %s", mail)
 
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,112 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Component is a part of a service started and stopped by the Lifecycle.
+type Component struct {
+	Name string
+	// Start runs the component until its ctx is cancelled, nil when there is nothing to run
+	Start func(ctx context.Context) error
+	// Stop releases the component, nil when cancelling the ctx of Start is enough
+	Stop func(ctx context.Context) error
+}
+
+// Closer returns a component that only closes a resource on shutdown.
+func Closer(name string, close func() error) Component {
+	return Component{
+		Name: name,
+		Stop: func(ctx context.Context) error { return close() },
+	}
+}
+
+// Lifecycle starts components in the order they are added and stops them in reverse order.
+type Lifecycle struct {
+	timeout    time.Duration
+	components []Component
+}
+
+func New(timeout time.Duration) *Lifecycle {
+	return &Lifecycle{timeout: timeout}
+}
+
+func (l *Lifecycle) Add(c Component) {
+	l.components = append(l.components, c)
+}
+
+type running struct {
+	Component
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Run starts every component and blocks until SIGINT, SIGTERM or a component failing,
+// then stops the components in reverse order within the shutdown timeout.
+func (l *Lifecycle) Run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return l.run(ctx)
+}
+
+func (l *Lifecycle) run(ctx context.Context) error {
+	failed := make(chan error, len(l.components))
+	started := make([]running, 0, len(l.components))
+	for _, c := range l.components {
+		componentCtx, cancel := context.WithCancel(context.Background())
+		r := running{Component: c, cancel: cancel, done: make(chan struct{})}
+		started = append(started, r)
+
+		if c.Start == nil {
+			close(r.done)
+			continue
+		}
+		go func() {
+			defer close(r.done)
+			if err := r.Start(componentCtx); err != nil {
+				failed <- fmt.Errorf("%s: %w", r.Name, err)
+			}
+		}()
+	}
+
+	var err error
+	select {
+	case <-ctx.Done():
+		log.Printf("shutting down")
+	case err = <-failed:
+		log.Printf("shutting down: %v", err)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), l.timeout)
+	defer cancel()
+	for i := len(started) - 1; i >= 0; i-- {
+		r := started[i]
+		r.cancel()
+		if r.Stop != nil {
+			if stopErr := r.Stop(shutdownCtx); stopErr != nil {
+				err = errors.Join(err, fmt.Errorf("stop %s: %w", r.Name, stopErr))
+			}
+		}
+
+		// wait for Start to return, a consumer finishes the message it is handling
+		select {
+		case <-r.done:
+		case <-shutdownCtx.Done():
+			select {
+			case <-r.done:
+			default:
+				err = errors.Join(err, fmt.Errorf("stop %s: %w", r.Name, shutdownCtx.Err()))
+			}
+		}
+	}
+
+	return err
+}
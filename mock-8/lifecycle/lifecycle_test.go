@@ -0,0 +1,115 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// recorder collects the order components are stopped in
+type recorder struct {
+	mu      sync.Mutex
+	stopped []string
+}
+
+func (r *recorder) component(name string, started chan<- struct{}) Component {
+	return Component{
+		Name: name,
+		Start: func(ctx context.Context) error {
+			started <- struct{}{}
+			<-ctx.Done()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.stopped = append(r.stopped, name)
+			return nil
+		},
+	}
+}
+
+func TestRunStopsInReverseOrderOnSignal(t *testing.T) {
+	rec := &recorder{}
+	started := make(chan struct{}, 3)
+
+	l := New(time.Second)
+	l.Add(rec.component("database", started))
+	l.Add(rec.component("consumer", started))
+	l.Add(Closer("channel", func() error {
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		rec.stopped = append(rec.stopped, "channel")
+		return nil
+	}))
+	l.Add(rec.component("http", started))
+
+	done := make(chan error, 1)
+	go func() { done <- l.Run() }()
+
+	// the signal handler is installed before any component starts
+	for i := 0; i < 3; i++ {
+		<-started
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after SIGTERM")
+	}
+
+	want := []string{"http", "channel", "consumer", "database"}
+	if !reflect.DeepEqual(rec.stopped, want) {
+		t.Errorf("stopped %v, want %v", rec.stopped, want)
+	}
+}
+
+func TestRunStopsWhenAComponentFails(t *testing.T) {
+	rec := &recorder{}
+	started := make(chan struct{}, 1)
+
+	l := New(time.Second)
+	l.Add(rec.component("database", started))
+	l.Add(Component{Name: "consumer", Start: func(ctx context.Context) error {
+		return errors.New("channel closed")
+	}})
+
+	err := l.run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "consumer: channel closed") {
+		t.Fatalf("err = %v, want the consumer failure", err)
+	}
+	if !reflect.DeepEqual(rec.stopped, []string{"database"}) {
+		t.Errorf("stopped %v, want [database]", rec.stopped)
+	}
+}
+
+func TestRunReportsComponentsOutlivingTheTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	release := make(chan struct{})
+	defer close(release)
+
+	l := New(50 * time.Millisecond)
+	l.Add(Component{Name: "stuck", Start: func(context.Context) error {
+		<-release
+		return nil
+	}})
+
+	err := l.run(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "stop stuck") {
+		t.Fatalf("err = %v, want stop stuck: %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	grpcServerHandled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_server_handled_total",
+		Help:      "gRPC calls handled by the server, by method and code.",
+	}, []string{"method", "code"})
+	grpcServerDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_server_handling_seconds",
+		Help:      "gRPC call latency on the server, by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+	grpcClientHandled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_client_handled_total",
+		Help:      "gRPC calls made by the client, by method and code.",
+	}, []string{"method", "code"})
+	grpcClientDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_client_handling_seconds",
+		Help:      "gRPC call latency seen by the client, by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+)
+
+// UnaryServerInterceptor records the code and latency of every call handled by the server.
+func UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	res, err := handler(ctx, req)
+
+	grpcServerHandled.WithLabelValues(info.FullMethod, status.Code(err).String()).Inc()
+	grpcServerDuration.WithLabelValues(info.FullMethod).Observe(time.Since(start).Seconds())
+	return res, err
+}
+
+// UnaryClientInterceptor records the code and latency of every call made by the client.
+func UnaryClientInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	start := time.Now()
+	err := invoker(ctx, method, req, reply, cc, opts...)
+
+	grpcClientHandled.WithLabelValues(method, status.Code(err).String()).Inc()
+	grpcClientDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	return err
+}
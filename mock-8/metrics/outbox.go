@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"log"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	outboxPendingDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "outbox", "pending_messages"),
+		"Outbox messages not published yet.",
+		nil, nil,
+	)
+	outboxOldestAgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "outbox", "oldest_pending_age_seconds"),
+		"Age of the oldest outbox message not published yet, 0 when the outbox is drained.",
+		nil, nil,
+	)
+)
+
+// OutboxStatsFunc returns the number of pending outbox messages and when the oldest one was created.
+type OutboxStatsFunc func() (pending int64, oldestPendingAt *time.Time, err error)
+
+// outboxCollector reads the outbox on every scrape
+type outboxCollector struct {
+	stats OutboxStatsFunc
+}
+
+// RegisterOutbox exposes the pending count and the age of the oldest pending message of the outbox.
+func RegisterOutbox(stats OutboxStatsFunc) {
+	prometheus.MustRegister(&outboxCollector{stats: stats})
+}
+
+func (c *outboxCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- outboxPendingDesc
+	ch <- outboxOldestAgeDesc
+}
+
+func (c *outboxCollector) Collect(ch chan<- prometheus.Metric) {
+	pending, oldestPendingAt, err := c.stats()
+	if err != nil {
+		log.Printf("failed to read outbox stats: %v", err)
+		return
+	}
+
+	var age float64
+	if oldestPendingAt != nil {
+		age = time.Since(*oldestPendingAt).Seconds()
+	}
+	ch <- prometheus.MustNewConstMetric(outboxPendingDesc, prometheus.GaugeValue, float64(pending))
+	ch <- prometheus.MustNewConstMetric(outboxOldestAgeDesc, prometheus.GaugeValue, age)
+}
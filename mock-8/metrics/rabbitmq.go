@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// outcome of a consumed message
+const (
+	ConsumeSent         = "sent"
+	ConsumeRetried      = "retried"
+	ConsumeDeadLettered = "dead_lettered"
+	ConsumeRequeued     = "requeued"
+)
+
+var (
+	rabbitmqPublished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rabbitmq_published_total",
+		Help:      "Messages published to RabbitMQ, by queue.",
+	}, []string{"queue"})
+	rabbitmqPublishFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rabbitmq_publish_failures_total",
+		Help:      "Messages that could not be published to RabbitMQ, by queue.",
+	}, []string{"queue"})
+	// messages consumed by outcome, every outcome but sent is a failure
+	RabbitMQConsumed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rabbitmq_consumed_total",
+		Help:      "Messages consumed from RabbitMQ, by queue and result.",
+	}, []string{"queue", "result"})
+)
+
+// ObservePublish counts a publish to queue and whether it failed.
+func ObservePublish(queue string, err error) {
+	if err != nil {
+		rabbitmqPublishFailures.WithLabelValues(queue).Inc()
+		return
+	}
+	rabbitmqPublished.WithLabelValues(queue).Inc()
+}
+
+var queueDepthDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "rabbitmq", "queue_messages"),
+	"Messages ready in a RabbitMQ queue.",
+	[]string{"queue"}, nil,
+)
+
+// queueDepthCollector reads the queue depth on every scrape
+type queueDepthCollector struct {
+	conn   *amqp.Connection
+	queues []string
+}
+
+// RegisterQueueDepth exposes the number of ready messages of queues.
+func RegisterQueueDepth(conn *amqp.Connection, queues ...string) {
+	prometheus.MustRegister(&queueDepthCollector{conn: conn, queues: queues})
+}
+
+func (c *queueDepthCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- queueDepthDesc
+}
+
+func (c *queueDepthCollector) Collect(ch chan<- prometheus.Metric) {
+	if c.conn.IsClosed() {
+		return
+	}
+
+	for _, name := range c.queues {
+		// a passive declare of a missing queue closes the channel, so each queue gets its own
+		channel, err := c.conn.Channel()
+		if err != nil {
+			return
+		}
+		q, err := channel.QueueDeclarePassive(name, true, false, false, false, nil)
+		if err == nil {
+			ch <- prometheus.MustNewConstMetric(queueDepthDesc, prometheus.GaugeValue, float64(q.Messages), name)
+			channel.Close()
+		}
+	}
+}
@@ -1,26 +1,51 @@
 package main
 
 import (
+	"context"
 	"final_project-ftgo-h8/config"
-	"final_project-ftgo-h8/helper"
+	"final_project-ftgo-h8/health"
+	"final_project-ftgo-h8/lifecycle"
+	"final_project-ftgo-h8/metrics"
 	"final_project-ftgo-h8/pb"
 	"final_project-ftgo-h8/product-service/repository"
 	"final_project-ftgo-h8/product-service/server"
+	"final_project-ftgo-h8/tracing"
+	"errors"
 	"log"
 	"net"
+	"net/http"
+	"time"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 func main() {
-    // load env
-    helper.LoadEnv()
+    // load config
+    cfg := config.MustLoad(config.ServiceProduct)
+
+    // components are stopped in reverse order on shutdown
+    app := lifecycle.New(cfg.ShutdownTimeout)
+
+    // init tracing, added first so spans are flushed after everything else stopped
+    shutdownTracing, err := tracing.Setup(config.ServiceProduct, cfg.Tracing)
+    if err != nil {
+        log.Fatal(err)
+    }
+    app.Add(lifecycle.Component{Name: "tracing", Stop: shutdownTracing})
 
 	// init db connection
-    db := config.NewGorm()
+    db := config.NewGorm(cfg.Database)
+    sqlDb, err := db.DB()
+    if err != nil {
+        log.Fatalf("Failed to get database connection: %v", err)
+    }
+    app.Add(lifecycle.Closer("postgres", sqlDb.Close))
 
     // Create a new gRPC server
-    grpcServer := grpc.NewServer()
+    grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(otelgrpc.UnaryServerInterceptor(), metrics.UnaryServerInterceptor, server.AdminUnaryInterceptor))
 
     // Create a new ProductRepository
     productRepo := repository.NewProductRepository(db)
@@ -31,16 +56,83 @@ func main() {
     // Register the ProductServiceServer with the gRPC server
     pb.RegisterProductServiceServer(grpcServer, productServer)
 
+    // Register the grpc.health.v1 service, serving while the database answers
+    healthServer := grpchealth.NewServer()
+    healthpb.RegisterHealthServer(grpcServer, healthServer)
+    checker := health.NewChecker(cfg.HealthCheckTimeout)
+    checker.Add("postgres", health.SQL(sqlDb))
+
+    // Return the stock of expired reservations
+    app.Add(lifecycle.Component{
+        Name: "reservation expiry",
+        Start: func(ctx context.Context) error {
+            productServer.ReleaseExpiredReservations(ctx, time.Minute)
+            return nil
+        },
+    })
+
+    // Take lots past their best before date out of stock
+    app.Add(lifecycle.Component{
+        Name: "stock lot expiry",
+        Start: func(ctx context.Context) error {
+            productServer.ExpireStockLots(ctx, time.Hour)
+            return nil
+        },
+    })
+
+    // Serve metrics over http
+    metricsServer := &http.Server{Addr: cfg.ProductService.MetricsAddr, Handler: metrics.Handler()}
+    app.Add(lifecycle.Component{
+        Name: "metrics server",
+        Start: func(ctx context.Context) error {
+            if err := metricsServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+                return err
+            }
+            return nil
+        },
+        Stop: metricsServer.Shutdown,
+    })
+
     // Listen on a port
-    lis, err := net.Listen("tcp", ":50051")
+    lis, err := net.Listen("tcp", cfg.ProductService.Addr)
     if err != nil {
         log.Fatalf("Failed to listen: %v", err)
     }
 
-    log.Println("Server is listening on port 50051")
+    log.Printf("Server is listening on %s", cfg.ProductService.Addr)
+
+    // Start the gRPC server, in-flight calls are finished on shutdown
+    app.Add(lifecycle.Component{
+        Name: "grpc server",
+        Start: func(ctx context.Context) error {
+            return grpcServer.Serve(lis)
+        },
+        Stop: func(ctx context.Context) error {
+            stopped := make(chan struct{})
+            go func() {
+                grpcServer.GracefulStop()
+                close(stopped)
+            }()
+
+            select {
+            case <-stopped:
+                return nil
+            case <-ctx.Done():
+                grpcServer.Stop()
+                return ctx.Err()
+            }
+        },
+    })
 
-    // Start the gRPC server
-    if err := grpcServer.Serve(lis); err != nil {
-        log.Fatalf("Failed to serve: %v", err)
+    // Keep the health status up to date, added last so it reports not serving before the server stops
+    app.Add(lifecycle.Component{
+        Name: "grpc health",
+        Start: func(ctx context.Context) error {
+            checker.ServeGRPC(ctx, healthServer, 10*time.Second, pb.ProductService_ServiceDesc.ServiceName)
+            return nil
+        },
+    })
+    if err := app.Run(); err != nil {
+        log.Fatal(err)
     }
 }
\ No newline at end of file
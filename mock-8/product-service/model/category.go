@@ -0,0 +1,9 @@
+package model
+
+// Category groups products, a category with a parent is a sub category (Seafood > Shellfish > Crab).
+type Category struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+	ParentID *uint  `json:"parent_id"`
+}
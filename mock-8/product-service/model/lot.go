@@ -0,0 +1,37 @@
+package model
+
+import (
+	"final_project-ftgo-h8/quantity"
+	"time"
+)
+
+// stock lot status
+const (
+	LotStatusAvailable = "available"
+	LotStatusDepleted  = "depleted"
+	LotStatusExpired   = "expired"
+)
+
+// StockLot is one batch of a product from a single catch. Available lots are sold
+// first-expired-first-out and expire after their best before date.
+type StockLot struct {
+	ID         uint              `json:"id"`
+	ProductID  uint              `json:"product_id"`
+	LotNumber  string            `json:"lot_number"`
+	CatchDate  time.Time         `json:"catch_date"`
+	OriginPort string            `json:"origin_port"`
+	Supplier   string            `json:"supplier"`
+	BestBefore time.Time         `json:"best_before"`
+	Quantity   quantity.Quantity `json:"quantity"`
+	Remaining  quantity.Quantity `json:"remaining"`
+	Status     string            `json:"status"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+// StockReservationLot is the part of a reservation item taken from one lot.
+type StockReservationLot struct {
+	ID                uint              `json:"id"`
+	ReservationItemID uint              `json:"reservation_item_id"`
+	LotID             uint              `json:"lot_id"`
+	Quantity          quantity.Quantity `json:"quantity"`
+}
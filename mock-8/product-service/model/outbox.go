@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// OutboxMessage is an event saved in the same transaction as the stock change it describes,
+// the outbox relay of the api gateway publishes it afterwards.
+type OutboxMessage struct {
+	ID          uint      `json:"id"`
+	Queue       string    `json:"queue"`
+	EventType   string    `json:"event_type"`
+	Payload     []byte    `json:"payload"`
+	TraceParent string    `json:"trace_parent,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (OutboxMessage) TableName() string {
+	return "outbox"
+}
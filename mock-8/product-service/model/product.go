@@ -1,9 +1,38 @@
 package model
 
+import "final_project-ftgo-h8/quantity"
+
 type Product struct {
-	ID          uint    `json:"id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       int64 `json:"price"`
-	Stock       int32   `json:"stock"`
-}
\ No newline at end of file
+	ID          uint              `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Price       int64             `json:"price"`
+	Stock       quantity.Quantity `json:"stock"`
+	// unit price and stock are counted in, the smallest order step is OrderIncrement
+	Unit           string            `json:"unit"`
+	OrderIncrement quantity.Quantity `json:"order_increment"`
+	// the seller or admins are alerted when stock drops below it, 0 turns alerts off
+	LowStockThreshold quantity.Quantity `json:"low_stock_threshold"`
+	CategoryID        *uint             `json:"category_id"`
+	// user id of the seller, nil for products sold by the marketplace itself
+	SellerID *uint          `json:"seller_id"`
+	Tags     []ProductTag   `gorm:"foreignKey:ProductID" json:"tags"`
+	Images   []ProductImage `gorm:"foreignKey:ProductID" json:"images"`
+	// kept up to date by the review repository, read only here
+	RatingAverage float64 `gorm:"->" json:"rating_average"`
+	ReviewCount   int64   `gorm:"->" json:"review_count"`
+}
+
+type ProductTag struct {
+	ProductID uint   `gorm:"primaryKey" json:"product_id"`
+	Tag       string `gorm:"primaryKey" json:"tag"`
+}
+
+// ProductImage is an uploaded product image, images are shown in position order.
+type ProductImage struct {
+	ID           uint   `json:"id"`
+	ProductID    uint   `json:"product_id"`
+	Position     int    `json:"position"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"final_project-ftgo-h8/quantity"
+	"time"
+)
+
+// stock reservation status
+const (
+	ReservationStatusReserved  = "reserved"
+	ReservationStatusCommitted = "committed"
+	ReservationStatusReleased  = "released"
+)
+
+type StockReservation struct {
+	ID        string                 `gorm:"primaryKey" json:"id"`
+	Status    string                 `json:"status"`
+	ExpiresAt time.Time              `json:"expires_at"`
+	CreatedAt time.Time              `json:"created_at"`
+	Items     []StockReservationItem `gorm:"foreignKey:ReservationID" json:"items"`
+}
+
+type StockReservationItem struct {
+	ID            uint              `json:"id"`
+	ReservationID string            `json:"reservation_id"`
+	ProductID     uint              `json:"product_id"`
+	Name          string            `json:"name"`
+	Price         int64             `json:"price"`
+	Quantity      quantity.Quantity `json:"quantity"`
+	Unit          string                `json:"unit"`
+	Lots          []StockReservationLot `gorm:"foreignKey:ReservationItemID" json:"lots"`
+}
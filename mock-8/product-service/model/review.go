@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// Review is a buyer rating of a delivered order line, each line can be reviewed once.
+// Hidden reviews are kept for moderation but left out of listings and the product rating.
+type Review struct {
+	ID            uint      `json:"id"`
+	ProductID     uint      `json:"product_id"`
+	UserID        uint      `json:"user_id"`
+	OrderDetailID uint      `json:"order_detail_id"`
+	Rating        int       `json:"rating"`
+	Body          string    `json:"body"`
+	Hidden        bool      `json:"hidden"`
+	CreatedAt     time.Time `json:"created_at"`
+}
@@ -0,0 +1,8 @@
+package model
+
+// SearchResult is a product matching a search query with its relevance.
+type SearchResult struct {
+	Product *Product `json:"product"`
+	Rank    float64  `json:"rank"`
+	Snippet string   `json:"snippet"`
+}
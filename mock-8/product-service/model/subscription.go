@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// RestockSubscription asks for an email when an out of stock product is restocked,
+// it is removed once the email is sent.
+type RestockSubscription struct {
+	ProductID uint      `gorm:"primaryKey" json:"product_id"`
+	UserID    uint      `gorm:"primaryKey" json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"errors"
+	"final_project-ftgo-h8/product-service/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+    ErrCategoryCycle = errors.New("category can not be its own ancestor")
+    ErrCategoryInUse = errors.New("category still has sub categories or products")
+)
+
+func (r *ProductRepositoryImpl) CreateCategory(category *model.Category) error {
+    // insert category into database
+    return r.db.Create(category).Error
+}
+
+func (r *ProductRepositoryImpl) GetAllCategories() ([]*model.Category, error) {
+    // retrieve all categories
+    var categories []*model.Category
+    if err := r.db.Order("id").Find(&categories).Error; err != nil {
+        return nil, err
+    }
+    return categories, nil
+}
+
+func (r *ProductRepositoryImpl) GetCategoryByID(id uint) (*model.Category, error) {
+    var category model.Category
+    if err := r.db.First(&category, id).Error; err != nil {
+        return nil, err
+    }
+    return &category, nil
+}
+
+func (r *ProductRepositoryImpl) GetCategoryBySlug(slug string) (*model.Category, error) {
+    var category model.Category
+    if err := r.db.Where("slug = ?", slug).First(&category).Error; err != nil {
+        return nil, err
+    }
+    return &category, nil
+}
+
+func (r *ProductRepositoryImpl) UpdateCategory(category *model.Category) error {
+    return r.db.Transaction(func(tx *gorm.DB) error {
+        // walk up from the new parent, the category must not be found on the way.
+        // ancestors are locked so a concurrent re-parent waits and then sees this one,
+        // two re-parents locking each other's category deadlock and one is aborted
+        parentID := category.ParentID
+        for parentID != nil {
+            if *parentID == category.ID {
+                return ErrCategoryCycle
+            }
+
+            var parent model.Category
+            if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&parent, *parentID).Error; err != nil {
+                return err
+            }
+            parentID = parent.ParentID
+        }
+
+        // update category in the database
+        return tx.Save(category).Error
+    })
+}
+
+func (r *ProductRepositoryImpl) DeleteCategoryByID(id uint) error {
+    return r.db.Transaction(func(tx *gorm.DB) error {
+        // only empty categories can be deleted
+        var children, products int64
+        if err := tx.Model(&model.Category{}).Where("parent_id = ?", id).Count(&children).Error; err != nil {
+            return err
+        }
+        if err := tx.Model(&model.Product{}).Where("category_id = ?", id).Count(&products).Error; err != nil {
+            return err
+        }
+        if children > 0 || products > 0 {
+            return ErrCategoryInUse
+        }
+
+        // delete category from the database
+        return tx.Delete(&model.Category{}, id).Error
+    })
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+    "errors"
+    "testing"
+
+    "final_project-ftgo-h8/product-service/model"
+
+    "github.com/DATA-DOG/go-sqlmock"
+    "gorm.io/driver/postgres"
+    "gorm.io/gorm"
+    "gorm.io/gorm/logger"
+)
+
+func newMockRepository(t *testing.T) (*ProductRepositoryImpl, sqlmock.Sqlmock) {
+    db, mock, err := sqlmock.New()
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { db.Close() })
+
+    gormDb, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{Logger: logger.Discard})
+    if err != nil {
+        t.Fatal(err)
+    }
+    return &ProductRepositoryImpl{db: gormDb}, mock
+}
+
+func uintPtr(n uint) *uint {
+    return &n
+}
+
+func TestUpdateCategoryDirectCycle(t *testing.T) {
+    r, mock := newMockRepository(t)
+
+    // nothing is read or written
+    mock.ExpectBegin()
+    mock.ExpectRollback()
+
+    err := r.UpdateCategory(&model.Category{ID: 3, Name: "Shellfish", Slug: "shellfish", ParentID: uintPtr(3)})
+    if !errors.Is(err, ErrCategoryCycle) {
+        t.Fatalf("err = %v, want %v", err, ErrCategoryCycle)
+    }
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Error(err)
+    }
+}
+
+func TestUpdateCategoryLocksAncestors(t *testing.T) {
+    r, mock := newMockRepository(t)
+    columns := []string{"id", "name", "slug", "parent_id"}
+
+    // Seafood(1) > Shellfish(3) > Crab(4), moving Seafood under Crab
+    mock.ExpectBegin()
+    mock.ExpectQuery(`SELECT \* FROM "categories" WHERE "categories"."id" = \$1 .* FOR UPDATE`).
+        WithArgs(4).
+        WillReturnRows(sqlmock.NewRows(columns).AddRow(4, "Crab", "crab", 3))
+    mock.ExpectQuery(`SELECT \* FROM "categories" WHERE "categories"."id" = \$1 .* FOR UPDATE`).
+        WithArgs(3).
+        WillReturnRows(sqlmock.NewRows(columns).AddRow(3, "Shellfish", "shellfish", 1))
+    mock.ExpectRollback()
+
+    err := r.UpdateCategory(&model.Category{ID: 1, Name: "Seafood", Slug: "seafood", ParentID: uintPtr(4)})
+    if !errors.Is(err, ErrCategoryCycle) {
+        t.Fatalf("err = %v, want %v", err, ErrCategoryCycle)
+    }
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Error(err)
+    }
+}
@@ -1,18 +1,59 @@
 package repository
 
 import (
+	"context"
 	"final_project-ftgo-h8/product-service/model"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type ProductRepository interface {
 	CreateProduct(product *model.Product) error
-	GetAllProducts() ([]*model.Product, error)
+	GetAllProducts(filter ProductFilter) ([]*model.Product, int64, error)
+	SearchProducts(q string, limit int, offset int) ([]*model.SearchResult, int64, error)
 	GetProductByID(id uint) (*model.Product, error)
-	UpdateProduct(product *model.Product) error
+	UpdateProduct(ctx context.Context, product *model.Product) error
 	DeleteProductByID(id uint) error
+	AddProductImage(image *model.ProductImage) error
+	ReserveStock(ctx context.Context, reservationID string, items []model.StockReservationItem, expiresAt time.Time) (*model.StockReservation, error)
+	GetReservationByID(id string) (*model.StockReservation, error)
+	CommitReservation(id string) (*model.StockReservation, error)
+	ReleaseReservation(ctx context.Context, id string) (*model.StockReservation, error)
+	CancelReservation(ctx context.Context, id string) (*model.StockReservation, error)
+	ReleaseExpiredReservations(now time.Time) (int, error)
+	AddStockLot(ctx context.Context, lot *model.StockLot) error
+	GetStockLots(productID uint) ([]*model.StockLot, error)
+	GetFreshestCatchDate(productID uint) (*time.Time, error)
+	ExpireStockLots(now time.Time) (int, error)
+	CreateReview(review *model.Review) error
+	GetReviews(productID uint, limit int, offset int) ([]*model.Review, int64, error)
+	SetReviewHidden(id uint, hidden bool) (*model.Review, error)
+	DeleteReviewByID(id uint) (*model.Review, error)
+	SubscribeRestock(productID uint, userID uint) (*model.RestockSubscription, error)
+	UnsubscribeRestock(productID uint, userID uint) error
+	CreateCategory(category *model.Category) error
+	GetAllCategories() ([]*model.Category, error)
+	GetCategoryByID(id uint) (*model.Category, error)
+	GetCategoryBySlug(slug string) (*model.Category, error)
+	UpdateCategory(category *model.Category) error
+	DeleteCategoryByID(id uint) error
 }
+
+// filter, sort and page for product listing
+type ProductFilter struct {
+	Limit    int
+	Offset   int
+	MinPrice int64
+	MaxPrice int64
+	InStock  bool
+	Name     string
+	Sort     string
+	Category string
+	Tag      string
+	SellerID uint
+}
+
 type ProductRepositoryImpl struct {
 	db *gorm.DB
 }
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"final_project-ftgo-h8/product-service/model"
+	"final_project-ftgo-h8/quantity"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (r *ProductRepositoryImpl) AddStockLot(ctx context.Context, lot *model.StockLot) error {
+    return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        // lock product
+        var product model.Product
+        if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, lot.ProductID).Error; err != nil {
+            return err
+        }
+
+        // insert lot into database
+        lot.Remaining = lot.Quantity
+        lot.Status = model.LotStatusAvailable
+        lot.CreatedAt = time.Now()
+        if err := tx.Create(lot).Error; err != nil {
+            return err
+        }
+
+        // the lot adds to the sellable stock
+        before := product.Stock
+        if err := tx.Model(&product).Update("stock", gorm.Expr("stock + ?", lot.Quantity)).Error; err != nil {
+            return err
+        }
+        if err := tx.First(&product, product.ID).Error; err != nil {
+            return err
+        }
+        return notifyStockChange(tx, &product, before)
+    })
+}
+
+func (r *ProductRepositoryImpl) GetStockLots(productID uint) ([]*model.StockLot, error) {
+    // retrieve lots of a product, next to expire first
+    var lots []*model.StockLot
+    err := r.db.Where("product_id = ?", productID).Order("best_before, id").Find(&lots).Error
+    if err != nil {
+        return nil, err
+    }
+    return lots, nil
+}
+
+func (r *ProductRepositoryImpl) GetFreshestCatchDate(productID uint) (*time.Time, error) {
+    // latest catch date of the lots that can still be sold
+    var lot model.StockLot
+    err := r.db.Where("product_id = ? AND status = ? AND remaining > 0", productID, model.LotStatusAvailable).
+        Order("catch_date desc").First(&lot).Error
+    if err == gorm.ErrRecordNotFound {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    return &lot.CatchDate, nil
+}
+
+func (r *ProductRepositoryImpl) ExpireStockLots(now time.Time) (int, error) {
+    // products with lots past their best before date
+    var productIDs []uint
+    err := r.db.Model(&model.StockLot{}).Distinct("product_id").
+        Where("status = ? AND best_before < ?", model.LotStatusAvailable, startOfDay(now)).
+        Order("product_id").Pluck("product_id", &productIDs).Error
+    if err != nil {
+        return 0, err
+    }
+
+    expired := 0
+    for _, productID := range productIDs {
+        err := r.db.Transaction(func(tx *gorm.DB) error {
+            n, err := expireProductLots(tx, productID, now)
+            expired += n
+            return err
+        })
+        if err != nil {
+            return expired, err
+        }
+    }
+    return expired, nil
+}
+
+// lots are sellable through their best before date
+func startOfDay(now time.Time) time.Time {
+    return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+}
+
+// expireProductLots takes the lots of a product past their best before date out of stock.
+// It locks the product before its lots, the same order as reservations.
+func expireProductLots(tx *gorm.DB, productID uint, now time.Time) (int, error) {
+    var product model.Product
+    if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, productID).Error; err != nil {
+        return 0, err
+    }
+
+    var lots []model.StockLot
+    err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+        Where("product_id = ? AND status = ? AND best_before < ?", productID, model.LotStatusAvailable, startOfDay(now)).
+        Order("id").Find(&lots).Error
+    if err != nil {
+        return 0, err
+    }
+
+    for _, lot := range lots {
+        // the remaining quantity is no longer sellable
+        before := product.Stock
+        err := tx.Model(&product).Update("stock", gorm.Expr("GREATEST(stock - ?, 0)", lot.Remaining)).Error
+        if err != nil {
+            return 0, err
+        }
+        if err := tx.First(&product, product.ID).Error; err != nil {
+            return 0, err
+        }
+        if err := notifyStockChange(tx, &product, before); err != nil {
+            return 0, err
+        }
+
+        err = tx.Model(&lot).Update("status", model.LotStatusExpired).Error
+        if err != nil {
+            return 0, err
+        }
+    }
+    return len(lots), nil
+}
+
+// allocateLots takes q from the available lots of a product, earliest best before first.
+// Stock added before lots were tracked has no lot and is taken last.
+// Lots past their best before date are never taken, even before ExpireStockLots marks them.
+func allocateLots(tx *gorm.DB, productID uint, q quantity.Quantity) ([]model.StockReservationLot, error) {
+    var lots []model.StockLot
+    err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+        Where("product_id = ? AND status = ? AND remaining > 0 AND best_before >= ?", productID, model.LotStatusAvailable, startOfDay(time.Now())).
+        Order("best_before, id").Find(&lots).Error
+    if err != nil {
+        return nil, err
+    }
+
+    var allocations []model.StockReservationLot
+    for _, lot := range lots {
+        if q == 0 {
+            break
+        }
+
+        taken := lot.Remaining
+        if taken > q {
+            taken = q
+        }
+
+        updates := map[string]interface{}{"remaining": lot.Remaining - taken}
+        if lot.Remaining == taken {
+            updates["status"] = model.LotStatusDepleted
+        }
+        if err := tx.Model(&lot).Updates(updates).Error; err != nil {
+            return nil, err
+        }
+
+        allocations = append(allocations, model.StockReservationLot{LotID: lot.ID, Quantity: taken})
+        q -= taken
+    }
+
+    return allocations, nil
+}
+
+// returnLots puts released quantities back into their lots and returns the quantity of
+// lots that expired in the meantime, which is not sellable anymore.
+func returnLots(tx *gorm.DB, allocations []model.StockReservationLot) (quantity.Quantity, error) {
+    var writtenOff quantity.Quantity
+    for _, allocation := range allocations {
+        var lot model.StockLot
+        if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&lot, allocation.LotID).Error; err != nil {
+            return 0, err
+        }
+
+        if lot.Status == model.LotStatusExpired {
+            writtenOff += allocation.Quantity
+            continue
+        }
+
+        err := tx.Model(&lot).Updates(map[string]interface{}{
+            "remaining": lot.Remaining + allocation.Quantity,
+            "status":    model.LotStatusAvailable,
+        }).Error
+        if err != nil {
+            return 0, err
+        }
+    }
+    return writtenOff, nil
+}
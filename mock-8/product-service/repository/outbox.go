@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"final_project-ftgo-h8/event"
+	"final_project-ftgo-h8/product-service/model"
+	"final_project-ftgo-h8/tracing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// insertOutboxMessage saves an event in tx, it is published once tx is committed.
+// It keeps the trace of the request tx was started with.
+func insertOutboxMessage(tx *gorm.DB, eventType string, payload interface{}) error {
+    msgByte, err := event.Marshal(eventType, payload)
+    if err != nil {
+        return err
+    }
+
+    return tx.Create(&model.OutboxMessage{
+        Queue:       event.EmailNotificationQueue,
+        EventType:   eventType,
+        Payload:     msgByte,
+        TraceParent: tracing.TraceParent(tx.Statement.Context),
+        CreatedAt:   time.Now(),
+    }).Error
+}
@@ -1,9 +1,17 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"final_project-ftgo-h8/product-service/model"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var ErrProductInUse = errors.New("product has reservations or orders")
+
 func (r *ProductRepositoryImpl) CreateProduct(product *model.Product) error {
     // insert product into database
     result := r.db.Create(product)
@@ -13,38 +21,171 @@ func (r *ProductRepositoryImpl) CreateProduct(product *model.Product) error {
     return nil
 }
 
-func (r *ProductRepositoryImpl) GetAllProducts() ([]*model.Product, error) {
-    // retrieve products from database
+// sort query param to order by clause
+var productSorts = map[string]string{
+    "":           "id asc",
+    "price_asc":  "price asc, id asc",
+    "price_desc": "price desc, id asc",
+    "name_asc":   "name asc, id asc",
+    "name_desc":  "name desc, id asc",
+    "newest":     "id desc",
+}
+
+func IsValidProductSort(sort string) bool {
+    _, ok := productSorts[sort]
+    return ok
+}
+
+// likeEscaper makes the wildcards of a LIKE pattern literal, with ESCAPE '\'
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(s string) string {
+    return likeEscaper.Replace(s)
+}
+
+func (r *ProductRepositoryImpl) GetAllProducts(filter ProductFilter) ([]*model.Product, int64, error) {
+    // build filtered query
+    query := r.db.Model(&model.Product{})
+    if filter.MinPrice > 0 {
+        query = query.Where("price >= ?", filter.MinPrice)
+    }
+    if filter.MaxPrice > 0 {
+        query = query.Where("price <= ?", filter.MaxPrice)
+    }
+    if filter.InStock {
+        query = query.Where("stock > 0")
+    }
+    if filter.Name != "" {
+        query = query.Where(`name ILIKE ? ESCAPE '\'`, "%"+escapeLike(filter.Name)+"%")
+    }
+    if filter.Category != "" {
+        // the category and all of its sub categories
+        query = query.Where(`category_id IN (
+            WITH RECURSIVE sub AS (
+                SELECT id FROM categories WHERE slug = ?
+                UNION ALL
+                SELECT c.id FROM categories c JOIN sub ON c.parent_id = sub.id
+            ) SELECT id FROM sub)`, filter.Category)
+    }
+    if filter.Tag != "" {
+        query = query.Where("id IN (SELECT product_id FROM product_tags WHERE tag = ?)", filter.Tag)
+    }
+    if filter.SellerID != 0 {
+        query = query.Where("seller_id = ?", filter.SellerID)
+    }
+
+    // count all matching products
+    var total int64
+    if err := query.Count(&total).Error; err != nil {
+        return nil, 0, err
+    }
+
+    // retrieve one page of products from database
     var products []*model.Product
-    if err := r.db.Find(&products).Error; err != nil {
-        return nil, err
+    err := query.Preload("Tags").Preload("Images", orderImages).Order(productSorts[filter.Sort]).Limit(filter.Limit).Offset(filter.Offset).Find(&products).Error
+    if err != nil {
+        return nil, 0, err
     }
-    return products, nil
+    return products, total, nil
 }
 
 func (r *ProductRepositoryImpl) GetProductByID(id uint) (*model.Product, error) {
     // retrieve product by ID from database
     var product model.Product
-    if err := r.db.First(&product, id).Error; err != nil {
+    if err := r.db.Preload("Tags").Preload("Images", orderImages).First(&product, id).Error; err != nil {
         return nil, err
     }
     return &product, nil
 }
 
-func (r *ProductRepositoryImpl) UpdateProduct(product *model.Product) error {
-    // update product in the database
-    result := r.db.Save(product)
-    if result.Error != nil {
-        return result.Error
-    }
-    return nil
+func (r *ProductRepositoryImpl) UpdateProduct(ctx context.Context, product *model.Product) error {
+    return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        // lock product, the stock before the update decides the stock emails
+        var existing model.Product
+        if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&existing, product.ID).Error; err != nil {
+            return err
+        }
+
+        // stock only changes through lots, reservations and expiry, keep the locked value
+        product.Stock = existing.Stock
+
+        // update product in the database
+        if err := tx.Omit("Tags", "Images").Save(product).Error; err != nil {
+            return err
+        }
+        if err := notifyStockChange(tx, product, existing.Stock); err != nil {
+            return err
+        }
+
+        // replace product tags
+        if err := tx.Where("product_id = ?", product.ID).Delete(&model.ProductTag{}).Error; err != nil {
+            return err
+        }
+        for i := range product.Tags {
+            product.Tags[i].ProductID = product.ID
+        }
+        if len(product.Tags) > 0 {
+            if err := tx.Create(&product.Tags).Error; err != nil {
+                return err
+            }
+        }
+        return nil
+    })
 }
 
 func (r *ProductRepositoryImpl) DeleteProductByID(id uint) error {
-    // delete product from the database
-    result := r.db.Delete(&model.Product{}, id)
-    if result.Error != nil {
-        return result.Error
-    }
-    return nil
-}
\ No newline at end of file
+    return r.db.Transaction(func(tx *gorm.DB) error {
+        // products that were reserved or ordered stay for the order history
+        var reserved, ordered int64
+        if err := tx.Model(&model.StockReservationItem{}).Where("product_id = ?", id).Count(&reserved).Error; err != nil {
+            return err
+        }
+        if err := tx.Table("order_details").Where("product_id = ?", id).Count(&ordered).Error; err != nil {
+            return err
+        }
+        if reserved > 0 || ordered > 0 {
+            return ErrProductInUse
+        }
+
+        // delete product lots, tags, images, restock subscriptions and product from the database
+        if err := tx.Where("product_id = ?", id).Delete(&model.StockLot{}).Error; err != nil {
+            return err
+        }
+        if err := tx.Where("product_id = ?", id).Delete(&model.ProductTag{}).Error; err != nil {
+            return err
+        }
+        if err := tx.Where("product_id = ?", id).Delete(&model.RestockSubscription{}).Error; err != nil {
+            return err
+        }
+        if err := tx.Where("product_id = ?", id).Delete(&model.ProductImage{}).Error; err != nil {
+            return err
+        }
+        return tx.Delete(&model.Product{}, id).Error
+    })
+}
+
+// images are preloaded in position order
+func orderImages(db *gorm.DB) *gorm.DB {
+    return db.Order("position")
+}
+
+func (r *ProductRepositoryImpl) AddProductImage(image *model.ProductImage) error {
+    return r.db.Transaction(func(tx *gorm.DB) error {
+        // lock product so concurrent uploads get their own position
+        var product model.Product
+        if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, image.ProductID).Error; err != nil {
+            return err
+        }
+
+        // append image after the last one
+        var lastPosition int
+        err := tx.Model(&model.ProductImage{}).Where("product_id = ?", image.ProductID).
+            Select("COALESCE(MAX(position), 0)").Scan(&lastPosition).Error
+        if err != nil {
+            return err
+        }
+        image.Position = lastPosition + 1
+
+        return tx.Create(image).Error
+    })
+}
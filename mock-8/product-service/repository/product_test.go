@@ -0,0 +1,49 @@
+package repository
+
+import (
+    "testing"
+
+    "github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestEscapeLike(t *testing.T) {
+    tests := []struct {
+        in   string
+        want string
+    }{
+        {"tuna", "tuna"},
+        {"50%", `50\%`},
+        {"king_crab", `king\_crab`},
+        {`a\b`, `a\\b`},
+        {`%_\`, `\%\_\\`},
+    }
+
+    for _, tt := range tests {
+        if got := escapeLike(tt.in); got != tt.want {
+            t.Errorf("escapeLike(%q) = %q, want %q", tt.in, got, tt.want)
+        }
+    }
+}
+
+func TestGetAllProductsNameFilterIsLiteral(t *testing.T) {
+    r, mock := newMockRepository(t)
+
+    // a name of only wildcards must not match every product
+    mock.ExpectQuery(`SELECT count\(\*\) FROM "products" WHERE name ILIKE \$1 ESCAPE '\\'`).
+        WithArgs(`%\%\_%`).
+        WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+    mock.ExpectQuery(`SELECT \* FROM "products" WHERE name ILIKE \$1 ESCAPE '\\'`).
+        WithArgs(`%\%\_%`).
+        WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+    _, total, err := r.GetAllProducts(ProductFilter{Name: "%_", Limit: 10})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if total != 0 {
+        t.Errorf("total = %d, want 0", total)
+    }
+    if err := mock.ExpectationsWereMet(); err != nil {
+        t.Error(err)
+    }
+}
@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"final_project-ftgo-h8/product-service/model"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+    ErrInsufficientStock    = errors.New("insufficient stock")
+    ErrReservationClosed    = errors.New("reservation is already released")
+    ErrReservationCommitted = errors.New("reservation is already committed")
+    ErrQuantityIncrement    = errors.New("quantity does not match the order increment")
+)
+
+func (r *ProductRepositoryImpl) ReserveStock(ctx context.Context, reservationID string, items []model.StockReservationItem, expiresAt time.Time) (*model.StockReservation, error) {
+    reservation := &model.StockReservation{
+        ID:        reservationID,
+        Status:    model.ReservationStatusReserved,
+        ExpiresAt: expiresAt,
+        CreatedAt: time.Now(),
+    }
+
+    // lock products in id order so concurrent reservations can not deadlock
+    sort.Slice(items, func(i, j int) bool { return items[i].ProductID < items[j].ProductID })
+
+    existing := false
+    err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        // the reservation id makes retries idempotent
+        result := tx.Omit("Items").Clauses(clause.OnConflict{DoNothing: true}).Create(reservation)
+        if result.Error != nil {
+            return result.Error
+        }
+        if result.RowsAffected == 0 {
+            existing = true
+            return nil
+        }
+
+        for i := range items {
+            // expired lots leave the stock before it is checked
+            if _, err := expireProductLots(tx, items[i].ProductID, time.Now()); err != nil {
+                return err
+            }
+
+            // take stock only when enough is left
+            result := tx.Model(&model.Product{}).
+                Where("id = ? AND stock >= ?", items[i].ProductID, items[i].Quantity).
+                Update("stock", gorm.Expr("stock - ?", items[i].Quantity))
+            if result.Error != nil {
+                return result.Error
+            }
+
+            var product model.Product
+            if err := tx.First(&product, items[i].ProductID).Error; err != nil {
+                return err
+            }
+            if result.RowsAffected == 0 {
+                return ErrInsufficientStock
+            }
+            if !items[i].Quantity.IsMultipleOf(product.OrderIncrement) {
+                return fmt.Errorf("%w: %s is sold in steps of %s %s", ErrQuantityIncrement, product.Name, product.OrderIncrement, product.Unit)
+            }
+            if err := notifyStockChange(tx, &product, product.Stock+items[i].Quantity); err != nil {
+                return err
+            }
+
+            // take the quantity from lots, first expired first out
+            lots, err := allocateLots(tx, product.ID, items[i].Quantity)
+            if err != nil {
+                return err
+            }
+
+            items[i].Lots = lots
+            items[i].ReservationID = reservationID
+            items[i].Name = product.Name
+            items[i].Price = product.Price
+            items[i].Unit = product.Unit
+        }
+
+        return tx.Create(&items).Error
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    if existing {
+        return r.GetReservationByID(reservationID)
+    }
+
+    reservation.Items = items
+    return reservation, nil
+}
+
+func (r *ProductRepositoryImpl) GetReservationByID(id string) (*model.StockReservation, error) {
+    // retrieve reservation with its items
+    var reservation model.StockReservation
+    if err := r.db.Preload("Items.Lots").First(&reservation, "id = ?", id).Error; err != nil {
+        return nil, err
+    }
+    return &reservation, nil
+}
+
+func (r *ProductRepositoryImpl) CommitReservation(id string) (*model.StockReservation, error) {
+    // only a reserved reservation can be committed
+    result := r.db.Model(&model.StockReservation{}).
+        Where("id = ? AND status = ?", id, model.ReservationStatusReserved).
+        Update("status", model.ReservationStatusCommitted)
+    if result.Error != nil {
+        return nil, result.Error
+    }
+
+    reservation, err := r.GetReservationByID(id)
+    if err != nil {
+        return nil, err
+    }
+    if reservation.Status != model.ReservationStatusCommitted {
+        return nil, ErrReservationClosed
+    }
+    return reservation, nil
+}
+
+// reservation statuses each release path returns the stock of
+var (
+    // the stock of a paid order stays taken
+    releaseFrom = []string{model.ReservationStatusReserved}
+    // cancelled orders return committed stock too
+    cancelFrom = []string{model.ReservationStatusReserved, model.ReservationStatusCommitted}
+)
+
+func (r *ProductRepositoryImpl) ReleaseReservation(ctx context.Context, id string) (*model.StockReservation, error) {
+    return r.releaseReservation(ctx, id, releaseFrom...)
+}
+
+func (r *ProductRepositoryImpl) CancelReservation(ctx context.Context, id string) (*model.StockReservation, error) {
+    return r.releaseReservation(ctx, id, cancelFrom...)
+}
+
+func (r *ProductRepositoryImpl) ReleaseExpiredReservations(now time.Time) (int, error) {
+    // find reservations past their ttl
+    var ids []string
+    err := r.db.Model(&model.StockReservation{}).
+        Where("status = ? AND expires_at < ?", model.ReservationStatusReserved, now).
+        Pluck("id", &ids).Error
+    if err != nil {
+        return 0, err
+    }
+
+    released := 0
+    for _, id := range ids {
+        // skip reservations committed since the lookup
+        reservation, err := r.releaseReservation(context.Background(), id, releaseFrom...)
+        if errors.Is(err, ErrReservationCommitted) {
+            continue
+        }
+        if err != nil {
+            return released, err
+        }
+        if reservation.Status == model.ReservationStatusReleased {
+            released++
+        }
+    }
+    return released, nil
+}
+
+// releaseReservation returns the reserved stock when the reservation is in one of the from status.
+func (r *ProductRepositoryImpl) releaseReservation(ctx context.Context, id string, from ...string) (*model.StockReservation, error) {
+    var reservation model.StockReservation
+    err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Items.Lots").First(&reservation, "id = ?", id).Error; err != nil {
+            return err
+        }
+
+        release, err := canRelease(reservation.Status, from)
+        if err != nil || !release {
+            return err
+        }
+
+        for _, item := range reservation.Items {
+            // stock of lots that expired meanwhile is written off
+            writtenOff, err := returnLots(tx, item.Lots)
+            if err != nil {
+                return err
+            }
+
+            err = tx.Model(&model.Product{}).
+                Where("id = ?", item.ProductID).
+                Update("stock", gorm.Expr("stock + ?", item.Quantity-writtenOff)).Error
+            if err != nil {
+                return err
+            }
+
+            var product model.Product
+            if err := tx.First(&product, item.ProductID).Error; err != nil {
+                return err
+            }
+            if err := notifyStockChange(tx, &product, product.Stock-(item.Quantity-writtenOff)); err != nil {
+                return err
+            }
+        }
+
+        reservation.Status = model.ReservationStatusReleased
+        return tx.Model(&reservation).Update("status", reservation.Status).Error
+    })
+    if err != nil {
+        return nil, err
+    }
+    return &reservation, nil
+}
+
+// canRelease tells whether a reservation in status gives its stock back when released from the from statuses.
+// Releasing twice is a no-op, a committed reservation that may not be released is an error.
+func canRelease(status string, from []string) (bool, error) {
+    switch {
+    case status == model.ReservationStatusReleased:
+        return false, nil
+    case containsStatus(from, status):
+        return true, nil
+    case status == model.ReservationStatusCommitted:
+        return false, ErrReservationCommitted
+    }
+    return false, nil
+}
+
+func containsStatus(statuses []string, status string) bool {
+    for _, v := range statuses {
+        if v == status {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+    "errors"
+    "testing"
+
+    "final_project-ftgo-h8/product-service/model"
+)
+
+func TestCanRelease(t *testing.T) {
+    tests := []struct {
+        name    string
+        status  string
+        from    []string
+        release bool
+        err     error
+    }{
+        {"release reserved", model.ReservationStatusReserved, releaseFrom, true, nil},
+        {"release committed", model.ReservationStatusCommitted, releaseFrom, false, ErrReservationCommitted},
+        {"release released", model.ReservationStatusReleased, releaseFrom, false, nil},
+        {"cancel reserved", model.ReservationStatusReserved, cancelFrom, true, nil},
+        {"cancel committed", model.ReservationStatusCommitted, cancelFrom, true, nil},
+        {"cancel released", model.ReservationStatusReleased, cancelFrom, false, nil},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            release, err := canRelease(tt.status, tt.from)
+            if !errors.Is(err, tt.err) {
+                t.Fatalf("err = %v, want %v", err, tt.err)
+            }
+            if release != tt.release {
+                t.Errorf("release = %v, want %v", release, tt.release)
+            }
+        })
+    }
+}
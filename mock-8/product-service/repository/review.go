@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"errors"
+	"final_project-ftgo-h8/product-service/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrReviewExists = errors.New("order line has already been reviewed")
+
+func (r *ProductRepositoryImpl) CreateReview(review *model.Review) error {
+    return r.db.Transaction(func(tx *gorm.DB) error {
+        // lock product, the rating is recomputed one review at a time
+        var product model.Product
+        if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, review.ProductID).Error; err != nil {
+            return err
+        }
+
+        // one review per order line
+        var count int64
+        if err := tx.Model(&model.Review{}).Where("order_detail_id = ?", review.OrderDetailID).Count(&count).Error; err != nil {
+            return err
+        }
+        if count > 0 {
+            return ErrReviewExists
+        }
+
+        // insert review into database
+        review.CreatedAt = time.Now()
+        if err := tx.Create(review).Error; err != nil {
+            return err
+        }
+
+        return updateProductRating(tx, review.ProductID)
+    })
+}
+
+func (r *ProductRepositoryImpl) GetReviews(productID uint, limit int, offset int) ([]*model.Review, int64, error) {
+    query := r.db.Model(&model.Review{}).Where("product_id = ? AND NOT hidden", productID)
+
+    // count all visible reviews
+    var total int64
+    if err := query.Count(&total).Error; err != nil {
+        return nil, 0, err
+    }
+
+    // retrieve one page, newest first
+    var reviews []*model.Review
+    if err := query.Order("created_at desc, id desc").Limit(limit).Offset(offset).Find(&reviews).Error; err != nil {
+        return nil, 0, err
+    }
+    return reviews, total, nil
+}
+
+func (r *ProductRepositoryImpl) SetReviewHidden(id uint, hidden bool) (*model.Review, error) {
+    var review model.Review
+    err := r.db.Transaction(func(tx *gorm.DB) error {
+        if err := tx.First(&review, id).Error; err != nil {
+            return err
+        }
+
+        // lock product
+        if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&model.Product{}, review.ProductID).Error; err != nil {
+            return err
+        }
+
+        // hide or show review
+        if err := tx.Model(&review).Update("hidden", hidden).Error; err != nil {
+            return err
+        }
+
+        return updateProductRating(tx, review.ProductID)
+    })
+    if err != nil {
+        return nil, err
+    }
+    return &review, nil
+}
+
+func (r *ProductRepositoryImpl) DeleteReviewByID(id uint) (*model.Review, error) {
+    var review model.Review
+    err := r.db.Transaction(func(tx *gorm.DB) error {
+        if err := tx.First(&review, id).Error; err != nil {
+            return err
+        }
+
+        // lock product
+        if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&model.Product{}, review.ProductID).Error; err != nil {
+            return err
+        }
+
+        // delete review from the database
+        if err := tx.Delete(&review).Error; err != nil {
+            return err
+        }
+
+        return updateProductRating(tx, review.ProductID)
+    })
+    if err != nil {
+        return nil, err
+    }
+    return &review, nil
+}
+
+// updateProductRating recomputes the average rating and review count of a product from its visible reviews
+func updateProductRating(tx *gorm.DB, productID uint) error {
+    return tx.Exec(`
+        UPDATE products SET
+            rating_average = coalesce((SELECT avg(rating) FROM reviews WHERE product_id = @id AND NOT hidden), 0),
+            review_count = (SELECT count(*) FROM reviews WHERE product_id = @id AND NOT hidden)
+        WHERE id = @id`, map[string]interface{}{"id": productID}).Error
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"final_project-ftgo-h8/product-service/model"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// minimum trigram word similarity of a product name to match a misspelled query
+const searchSimilarityThreshold = 0.3
+
+// matches full-text hits on name and description and near matches on name,
+// both sides can use their GIN index. <% compares with pg_trgm.word_similarity_threshold.
+const searchWhere = `
+    search_vector @@ websearch_to_tsquery('indonesian', @q)
+    OR @q <% name`
+
+// product text is html escaped before ts_headline adds the <b> tags, the snippet is safe to render
+const searchSnippet = `
+    ts_headline('indonesian',
+        replace(replace(replace(coalesce(name, '') || ' - ' || coalesce(description, ''), '&', '&amp;'), '<', '&lt;'), '>', '&gt;'),
+        websearch_to_tsquery('indonesian', @q),
+        'StartSel=<b>, StopSel=</b>, MaxWords=20, MinWords=5')`
+
+func (r *ProductRepositoryImpl) SearchProducts(q string, limit int, offset int) ([]*model.SearchResult, int64, error) {
+    args := map[string]interface{}{"q": q, "limit": limit, "offset": offset}
+
+    var total int64
+    var hits []struct {
+        ID      uint
+        Rank    float64
+        Snippet string
+    }
+    err := r.db.Transaction(func(tx *gorm.DB) error {
+        // the threshold of <% only applies to this transaction
+        err := tx.Exec(`SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)`, fmt.Sprint(searchSimilarityThreshold)).Error
+        if err != nil {
+            return err
+        }
+
+        // count all matching products
+        err = tx.Raw(`SELECT count(*) FROM products WHERE `+searchWhere, args).Scan(&total).Error
+        if err != nil {
+            return err
+        }
+
+        // rank one page, full-text relevance plus name similarity for typos
+        return tx.Raw(`
+            SELECT id,
+                ts_rank(search_vector, websearch_to_tsquery('indonesian', @q)) + word_similarity(@q, name) AS rank,
+                `+searchSnippet+` AS snippet
+            FROM products
+            WHERE `+searchWhere+`
+            ORDER BY rank DESC, id
+            LIMIT @limit OFFSET @offset`, args).Scan(&hits).Error
+    })
+    if err != nil {
+        return nil, 0, err
+    }
+    if len(hits) == 0 {
+        return []*model.SearchResult{}, total, nil
+    }
+
+    // load the products of the page with their tags and images
+    ids := make([]uint, 0, len(hits))
+    for _, hit := range hits {
+        ids = append(ids, hit.ID)
+    }
+    var products []*model.Product
+    err = r.db.Preload("Tags").Preload("Images", orderImages).Find(&products, ids).Error
+    if err != nil {
+        return nil, 0, err
+    }
+    productsByID := map[uint]*model.Product{}
+    for _, product := range products {
+        productsByID[product.ID] = product
+    }
+
+    // keep the rank order
+    results := make([]*model.SearchResult, 0, len(hits))
+    for _, hit := range hits {
+        product, ok := productsByID[hit.ID]
+        if !ok {
+            continue
+        }
+        results = append(results, &model.SearchResult{Product: product, Rank: hit.Rank, Snippet: hit.Snippet})
+    }
+    return results, total, nil
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"errors"
+	"final_project-ftgo-h8/event"
+	"final_project-ftgo-h8/product-service/model"
+	"final_project-ftgo-h8/quantity"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrProductInStock = errors.New("product is in stock")
+
+// recipient of a stock email, read from the users of the api gateway
+type stockRecipient struct {
+    Email string
+    Name  string
+}
+
+func (r *ProductRepositoryImpl) SubscribeRestock(productID uint, userID uint) (*model.RestockSubscription, error) {
+    subscription := &model.RestockSubscription{
+        ProductID: productID,
+        UserID:    userID,
+        CreatedAt: time.Now(),
+    }
+    err := r.db.Transaction(func(tx *gorm.DB) error {
+        // only out of stock products can be subscribed to
+        var product model.Product
+        if err := tx.First(&product, productID).Error; err != nil {
+            return err
+        }
+        if product.Stock > 0 {
+            return ErrProductInStock
+        }
+
+        // subscribing twice keeps the first subscription
+        return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(subscription).Error
+    })
+    if err != nil {
+        return nil, err
+    }
+    return subscription, nil
+}
+
+func (r *ProductRepositoryImpl) UnsubscribeRestock(productID uint, userID uint) error {
+    result := r.db.Where("product_id = ? AND user_id = ?", productID, userID).Delete(&model.RestockSubscription{})
+    if result.Error != nil {
+        return result.Error
+    }
+    if result.RowsAffected == 0 {
+        return gorm.ErrRecordNotFound
+    }
+    return nil
+}
+
+// notifyStockChange queues the stock emails for a change of product stock from before to product.Stock,
+// a low stock alert when stock drops below the threshold and a back in stock email when it comes back from zero.
+func notifyStockChange(tx *gorm.DB, product *model.Product, before quantity.Quantity) error {
+    threshold := product.LowStockThreshold
+    if threshold > 0 && before >= threshold && product.Stock < threshold {
+        if err := notifyLowStock(tx, product); err != nil {
+            return err
+        }
+    }
+
+    if before <= 0 && product.Stock > 0 {
+        if err := notifyBackInStock(tx, product); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func notifyLowStock(tx *gorm.DB, product *model.Product) error {
+    // the seller of the product, or every admin for marketplace products
+    query := tx.Table("users").Select("email, name")
+    if product.SellerID != nil {
+        query = query.Where("id = ?", *product.SellerID)
+    } else {
+        query = query.Where("role = ?", "Admin")
+    }
+    var recipients []stockRecipient
+    if err := query.Order("id").Scan(&recipients).Error; err != nil {
+        return err
+    }
+
+    for _, recipient := range recipients {
+        err := insertOutboxMessage(tx, event.TypeLowStock, event.LowStock{
+            Email:       recipient.Email,
+            Name:        recipient.Name,
+            ProductId:   product.ID,
+            ProductName: product.Name,
+            Stock:       product.Stock,
+            Threshold:   product.LowStockThreshold,
+            Unit:        product.Unit,
+        })
+        if err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func notifyBackInStock(tx *gorm.DB, product *model.Product) error {
+    // every subscriber is emailed once
+    var recipients []stockRecipient
+    err := tx.Table("users").Select("users.email, users.name").
+        Joins("JOIN restock_subscriptions ON restock_subscriptions.user_id = users.id").
+        Where("restock_subscriptions.product_id = ?", product.ID).
+        Order("restock_subscriptions.created_at").Scan(&recipients).Error
+    if err != nil {
+        return err
+    }
+
+    for _, recipient := range recipients {
+        err := insertOutboxMessage(tx, event.TypeBackInStock, event.BackInStock{
+            Email:       recipient.Email,
+            Name:        recipient.Name,
+            ProductId:   product.ID,
+            ProductName: product.Name,
+            Stock:       product.Stock,
+            Unit:        product.Unit,
+        })
+        if err != nil {
+            return err
+        }
+    }
+
+    return tx.Where("product_id = ?", product.ID).Delete(&model.RestockSubscription{}).Error
+}
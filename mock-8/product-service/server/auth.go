@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"final_project-ftgo-h8/helper"
+	"final_project-ftgo-h8/product-service/model"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// user roles set by the api gateway
+const (
+	roleAdmin  = "Admin"
+	roleSeller = "Seller"
+)
+
+// methods that change the catalogue and need an admin caller
+var adminMethods = map[string]bool{
+	"/pb.ProductService/CreateCategory": true,
+	"/pb.ProductService/UpdateCategory": true,
+	"/pb.ProductService/DeleteCategory": true,
+	"/pb.ProductService/HideReview":     true,
+	"/pb.ProductService/DeleteReview":   true,
+	// returns the stock of paid orders
+	"/pb.ProductService/CancelReservation": true,
+}
+
+// methods that change products and need an admin or seller caller,
+// sellers are limited to their own products by the method itself
+var sellerMethods = map[string]bool{
+	"/pb.ProductService/CreateProduct":   true,
+	"/pb.ProductService/UpdateProduct":   true,
+	"/pb.ProductService/DeleteProduct":   true,
+	"/pb.ProductService/AddProductImage": true,
+	"/pb.ProductService/AddStockLot":     true,
+	"/pb.ProductService/GetAllStockLot":  true,
+}
+
+func AdminUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	role := helper.UserRoleFromMetadata(ctx)
+	if adminMethods[info.FullMethod] && role != roleAdmin {
+		return nil, status.Error(codes.PermissionDenied, "caller is not an admin")
+	}
+	if sellerMethods[info.FullMethod] && role != roleAdmin && role != roleSeller {
+		return nil, status.Error(codes.PermissionDenied, "caller is not an admin or seller")
+	}
+
+	return handler(ctx, req)
+}
+
+// authorizeProduct allows admins to manage every product and sellers only their own products
+func authorizeProduct(ctx context.Context, product *model.Product) error {
+	if helper.UserRoleFromMetadata(ctx) != roleSeller {
+		return nil
+	}
+
+	sellerID := helper.UserIdFromMetadata(ctx)
+	if product.SellerID == nil || sellerID == 0 || *product.SellerID != sellerID {
+		return status.Error(codes.PermissionDenied, "product is owned by another seller")
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+    "context"
+    "testing"
+
+    "final_project-ftgo-h8/helper"
+
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/metadata"
+    "google.golang.org/grpc/status"
+)
+
+func TestAdminUnaryInterceptorCancelReservation(t *testing.T) {
+    tests := []struct {
+        role string
+        want codes.Code
+    }{
+        {"", codes.PermissionDenied},
+        {"User", codes.PermissionDenied},
+        {roleSeller, codes.PermissionDenied},
+        {roleAdmin, codes.OK},
+    }
+
+    info := &grpc.UnaryServerInfo{FullMethod: "/pb.ProductService/CancelReservation"}
+    handler := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+
+    for _, tt := range tests {
+        t.Run(tt.role, func(t *testing.T) {
+            ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(helper.MetadataUserRole, tt.role))
+            _, err := AdminUnaryInterceptor(ctx, nil, info, handler)
+            if code := status.Code(err); code != tt.want {
+                t.Errorf("code = %v, want %v", code, tt.want)
+            }
+        })
+    }
+}
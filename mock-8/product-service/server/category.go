@@ -0,0 +1,177 @@
+package server
+
+import (
+    "context"
+    "errors"
+    "regexp"
+    "strconv"
+    "strings"
+
+    pb "final_project-ftgo-h8/pb"
+    "final_project-ftgo-h8/product-service/model"
+    "final_project-ftgo-h8/product-service/repository"
+
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+    "gorm.io/gorm"
+)
+
+var (
+    slugPattern     = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+    slugInvalidChar = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// toSlug turns a category name into a url slug, "Fresh Fish" becomes "fresh-fish"
+func toSlug(name string) string {
+    return strings.Trim(slugInvalidChar.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+func toPbCategory(category *model.Category) *pb.Category {
+    pbCategory := &pb.Category{
+        Id:   strconv.FormatUint(uint64(category.ID), 10),
+        Name: category.Name,
+        Slug: category.Slug,
+    }
+    if category.ParentID != nil {
+        pbCategory.ParentId = strconv.FormatUint(uint64(*category.ParentID), 10)
+    }
+    return pbCategory
+}
+
+// applyCategory validates the request category and copies it to category
+func (s *ProductServer) applyCategory(category *model.Category, req *pb.Category) error {
+    name := strings.TrimSpace(req.GetName())
+    if name == "" {
+        return status.Error(codes.InvalidArgument, "Invalid category data")
+    }
+
+    slug := req.GetSlug()
+    if slug == "" {
+        slug = toSlug(name)
+    }
+    if !slugPattern.MatchString(slug) {
+        return status.Error(codes.InvalidArgument, "slug must contain only lower case letters, digits and hyphens")
+    }
+
+    // parent must exist
+    var parentID *uint
+    if req.GetParentId() != "" {
+        id, err := strconv.ParseUint(req.GetParentId(), 10, 64)
+        if err != nil {
+            return status.Error(codes.InvalidArgument, "Invalid parent category ID")
+        }
+        if _, err := s.repo.GetCategoryByID(uint(id)); err != nil {
+            if err == gorm.ErrRecordNotFound {
+                return status.Error(codes.InvalidArgument, "Parent category not found")
+            }
+            return status.Error(codes.Internal, "Failed to retrieve category")
+        }
+        idUint := uint(id)
+        parentID = &idUint
+    }
+
+    // slug is unique
+    existing, err := s.repo.GetCategoryBySlug(slug)
+    if err != nil && err != gorm.ErrRecordNotFound {
+        return status.Error(codes.Internal, "Failed to retrieve category")
+    }
+    if err == nil && existing.ID != category.ID {
+        return status.Error(codes.AlreadyExists, "Category slug already exists")
+    }
+
+    category.Name = name
+    category.Slug = slug
+    category.ParentID = parentID
+    return nil
+}
+
+func (s *ProductServer) CreateCategory(ctx context.Context, req *pb.CreateCategoryRequest) (*pb.Category, error) {
+    category := &model.Category{}
+    if err := s.applyCategory(category, req.GetCategory()); err != nil {
+        return nil, err
+    }
+
+    if err := s.repo.CreateCategory(category); err != nil {
+        return nil, status.Error(codes.Internal, "Failed to create category")
+    }
+
+    return toPbCategory(category), nil
+}
+
+func (s *ProductServer) GetAllCategory(ctx context.Context, req *pb.GetAllCategoryRequest) (*pb.GetAllCategoryResponse, error) {
+    categories, err := s.repo.GetAllCategories()
+    if err != nil {
+        return nil, status.Error(codes.Internal, "Failed to retrieve categories")
+    }
+
+    response := &pb.GetAllCategoryResponse{}
+    for _, category := range categories {
+        response.Categories = append(response.Categories, toPbCategory(category))
+    }
+
+    return response, nil
+}
+
+func (s *ProductServer) GetCategory(ctx context.Context, req *pb.GetCategoryRequest) (*pb.Category, error) {
+    category, err := s.repo.GetCategoryBySlug(req.GetSlug())
+    if err != nil {
+        if err == gorm.ErrRecordNotFound {
+            return nil, status.Error(codes.NotFound, "Category not found")
+        }
+        return nil, status.Error(codes.Internal, "Failed to retrieve category")
+    }
+
+    return toPbCategory(category), nil
+}
+
+func (s *ProductServer) UpdateCategory(ctx context.Context, req *pb.UpdateCategoryRequest) (*pb.Category, error) {
+    categoryID, err := strconv.ParseUint(req.GetCategory().GetId(), 10, 64)
+    if err != nil {
+        return nil, status.Error(codes.InvalidArgument, "Invalid category ID")
+    }
+
+    category, err := s.repo.GetCategoryByID(uint(categoryID))
+    if err != nil {
+        if err == gorm.ErrRecordNotFound {
+            return nil, status.Error(codes.NotFound, "Category not found")
+        }
+        return nil, status.Error(codes.Internal, "Failed to retrieve category")
+    }
+
+    if err := s.applyCategory(category, req.GetCategory()); err != nil {
+        return nil, err
+    }
+
+    if err := s.repo.UpdateCategory(category); err != nil {
+        if errors.Is(err, repository.ErrCategoryCycle) {
+            return nil, status.Error(codes.InvalidArgument, err.Error())
+        }
+        return nil, status.Error(codes.Internal, "Failed to update category")
+    }
+
+    return toPbCategory(category), nil
+}
+
+func (s *ProductServer) DeleteCategory(ctx context.Context, req *pb.DeleteCategoryRequest) (*pb.Category, error) {
+    categoryID, err := strconv.ParseUint(req.GetId(), 10, 64)
+    if err != nil {
+        return nil, status.Error(codes.InvalidArgument, "Invalid category ID")
+    }
+
+    category, err := s.repo.GetCategoryByID(uint(categoryID))
+    if err != nil {
+        if err == gorm.ErrRecordNotFound {
+            return nil, status.Error(codes.NotFound, "Category not found")
+        }
+        return nil, status.Error(codes.Internal, "Failed to retrieve category")
+    }
+
+    if err := s.repo.DeleteCategoryByID(category.ID); err != nil {
+        if errors.Is(err, repository.ErrCategoryInUse) {
+            return nil, status.Error(codes.FailedPrecondition, err.Error())
+        }
+        return nil, status.Error(codes.Internal, "Failed to delete category")
+    }
+
+    return toPbCategory(category), nil
+}
@@ -10,6 +10,6 @@ type ProductServer struct {
 	repo repository.ProductRepository
 }
 
-func NewProductServer(repo repository.ProductRepository) pb.ProductServiceServer {
+func NewProductServer(repo repository.ProductRepository) *ProductServer {
 	return &ProductServer{repo: repo}
 }
\ No newline at end of file
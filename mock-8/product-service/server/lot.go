@@ -0,0 +1,130 @@
+package server
+
+import (
+    "context"
+    "log"
+    "strconv"
+    "strings"
+    "time"
+
+    pb "final_project-ftgo-h8/pb"
+    "final_project-ftgo-h8/product-service/model"
+    "final_project-ftgo-h8/quantity"
+
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+)
+
+func toPbStockLot(lot *model.StockLot) *pb.StockLot {
+    return &pb.StockLot{
+        Id:         strconv.FormatUint(uint64(lot.ID), 10),
+        ProductId:  strconv.FormatUint(uint64(lot.ProductID), 10),
+        LotNumber:  lot.LotNumber,
+        CatchDate:  lot.CatchDate.Format(time.DateOnly),
+        OriginPort: lot.OriginPort,
+        Supplier:   lot.Supplier,
+        BestBefore: lot.BestBefore.Format(time.DateOnly),
+        Quantity:   int64(lot.Quantity),
+        Remaining:  int64(lot.Remaining),
+        Status:     lot.Status,
+    }
+}
+
+func (s *ProductServer) AddStockLot(ctx context.Context, req *pb.AddStockLotRequest) (*pb.StockLot, error) {
+    newLot := req.GetLot()
+
+    productID, err := strconv.ParseUint(newLot.GetProductId(), 10, 64)
+    if err != nil {
+        return nil, status.Error(codes.InvalidArgument, "Invalid product ID")
+    }
+
+    // validate lot
+    catchDate, err := time.Parse(time.DateOnly, newLot.GetCatchDate())
+    if err != nil {
+        return nil, status.Error(codes.InvalidArgument, "catch_date must be formatted as YYYY-MM-DD")
+    }
+    bestBefore, err := time.Parse(time.DateOnly, newLot.GetBestBefore())
+    if err != nil {
+        return nil, status.Error(codes.InvalidArgument, "best_before must be formatted as YYYY-MM-DD")
+    }
+    if bestBefore.Before(catchDate) {
+        return nil, status.Error(codes.InvalidArgument, "best_before must not be before catch_date")
+    }
+    if strings.TrimSpace(newLot.GetLotNumber()) == "" || newLot.GetQuantity() <= 0 {
+        return nil, status.Error(codes.InvalidArgument, "Invalid stock lot data")
+    }
+
+    product, err := s.getOwnedProduct(ctx, uint(productID))
+    if err != nil {
+        return nil, err
+    }
+    if quantity.IsWholeUnit(product.Unit) && !quantity.Quantity(newLot.GetQuantity()).IsMultipleOf(quantity.FromInt(1)) {
+        return nil, status.Errorf(codes.InvalidArgument, "quantity of a %s must be a whole number", product.Unit)
+    }
+
+    lot := &model.StockLot{
+        ProductID:  product.ID,
+        LotNumber:  strings.TrimSpace(newLot.GetLotNumber()),
+        CatchDate:  catchDate,
+        OriginPort: newLot.GetOriginPort(),
+        Supplier:   newLot.GetSupplier(),
+        BestBefore: bestBefore,
+        Quantity:   quantity.Quantity(newLot.GetQuantity()),
+    }
+    if err := s.repo.AddStockLot(ctx, lot); err != nil {
+        return nil, status.Error(codes.Internal, "Failed to add stock lot")
+    }
+
+    return toPbStockLot(lot), nil
+}
+
+func (s *ProductServer) GetAllStockLot(ctx context.Context, req *pb.GetAllStockLotRequest) (*pb.GetAllStockLotResponse, error) {
+    productID, err := strconv.ParseUint(req.GetProductId(), 10, 64)
+    if err != nil {
+        return nil, status.Error(codes.InvalidArgument, "Invalid product ID")
+    }
+
+    if _, err := s.getOwnedProduct(ctx, uint(productID)); err != nil {
+        return nil, err
+    }
+
+    lots, err := s.repo.GetStockLots(uint(productID))
+    if err != nil {
+        return nil, status.Error(codes.Internal, "Failed to retrieve stock lots")
+    }
+
+    response := &pb.GetAllStockLotResponse{}
+    for _, lot := range lots {
+        response.Lots = append(response.Lots, toPbStockLot(lot))
+    }
+
+    return response, nil
+}
+
+// ExpireStockLots takes lots past their best before date out of stock right away and then
+// every interval until ctx is done.
+func (s *ProductServer) ExpireStockLots(ctx context.Context, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    s.expireStockLots(time.Now())
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case now := <-ticker.C:
+            s.expireStockLots(now)
+        }
+    }
+}
+
+func (s *ProductServer) expireStockLots(now time.Time) {
+    expired, err := s.repo.ExpireStockLots(now)
+    if err != nil {
+        log.Printf("Failed to expire stock lots: %v", err)
+        return
+    }
+    if expired > 0 {
+        log.Printf("Expired %d stock lots", expired)
+    }
+}
@@ -2,10 +2,17 @@ package server
 
 import (
     "context"
+    "errors"
     "strconv"
+    "strings"
+    "time"
+    "unicode"
 
+    "final_project-ftgo-h8/helper"
     pb "final_project-ftgo-h8/pb"
     "final_project-ftgo-h8/product-service/model"
+    "final_project-ftgo-h8/product-service/repository"
+    "final_project-ftgo-h8/quantity"
 
     "google.golang.org/grpc/codes"
     "google.golang.org/grpc/status"
@@ -13,9 +20,40 @@ import (
 )
 
 func (s *ProductServer) ValidateProduct(product *pb.Product) error {
-    if product.GetName() == "" || product.GetPrice() <= 0 || product.GetStock() < 0 {
+    if product.GetName() == "" || product.GetPrice() <= 0 {
         return status.Error(codes.InvalidArgument, "Invalid product data")
     }
+
+    // stock only comes from lots, which know when it expires
+    if product.GetStock() != 0 {
+        return status.Error(codes.InvalidArgument, "stock is added through stock lots")
+    }
+
+    // names end up in email subjects and headers, line breaks could add headers
+    if strings.IndexFunc(product.GetName(), unicode.IsControl) >= 0 {
+        return status.Error(codes.InvalidArgument, "name must not contain control characters")
+    }
+
+    // unit defaults to piece with the default increment of the unit
+    if product.GetUnit() == "" {
+        product.Unit = quantity.UnitPiece
+    }
+    if !quantity.IsValidUnit(product.GetUnit()) {
+        return status.Error(codes.InvalidArgument, "unit must be one of piece, kg, gram, pack")
+    }
+    if product.GetOrderIncrement() == 0 {
+        product.OrderIncrement = int64(quantity.DefaultIncrement(product.GetUnit()))
+    }
+    increment := quantity.Quantity(product.GetOrderIncrement())
+    if increment < 0 {
+        return status.Error(codes.InvalidArgument, "order_increment must be positive")
+    }
+    if quantity.IsWholeUnit(product.GetUnit()) && !increment.IsMultipleOf(quantity.FromInt(1)) {
+        return status.Errorf(codes.InvalidArgument, "order_increment of a %s must be a whole number", product.GetUnit())
+    }
+    if product.GetLowStockThreshold() < 0 {
+        return status.Error(codes.InvalidArgument, "low_stock_threshold must not be negative")
+    }
     return nil
 }
 
@@ -26,47 +64,105 @@ func (s *ProductServer) CreateProduct(ctx context.Context, req *pb.CreateProduct
         return nil, err
     }
 
+    categoryID, err := s.validateProductCategory(newProduct.GetCategoryId())
+    if err != nil {
+        return nil, err
+    }
+
+    sellerID, err := productSeller(ctx, newProduct.GetSellerId())
+    if err != nil {
+        return nil, err
+    }
+
     product := &model.Product{
-        Name:        newProduct.GetName(),
-        Description: newProduct.GetDescription(),
-        Price:       int64(newProduct.GetPrice()),
-        Stock:       int32(newProduct.GetStock()),
+        Name:              newProduct.GetName(),
+        Description:       newProduct.GetDescription(),
+        Price:             int64(newProduct.GetPrice()),
+        Unit:              newProduct.GetUnit(),
+        OrderIncrement:    quantity.Quantity(newProduct.GetOrderIncrement()),
+        LowStockThreshold: quantity.Quantity(newProduct.GetLowStockThreshold()),
+        CategoryID:        categoryID,
+        SellerID:          sellerID,
+        Tags:              toProductTags(newProduct.GetTags()),
     }
 
     if err := s.repo.CreateProduct(product); err != nil {
         return nil, status.Error(codes.Internal, "Failed to create product")
     }
 
-    createdProduct := &pb.Product{
-        Id:          strconv.FormatUint(uint64(product.ID), 10),
-        Name:        product.Name,
-        Description: product.Description,
-        Price:       int64(product.Price),
-        Stock:       int32(product.Stock),
-    }
+    createdProduct := toPbProduct(product)
 
     return createdProduct, nil
 }
 
+const (
+    defaultProductLimit = 10
+    maxProductLimit     = 100
+)
+
 func (s *ProductServer) GetAllProduct(ctx context.Context, req *pb.GetAllProductRequest) (*pb.GetAllProductResponse, error) {
-    products, err := s.repo.GetAllProducts()
+    // validate page
+    limit := int(req.GetLimit())
+    if limit == 0 {
+        limit = defaultProductLimit
+    }
+    if limit < 0 || limit > maxProductLimit {
+        return nil, status.Errorf(codes.InvalidArgument, "limit must be between 1 and %d", maxProductLimit)
+    }
+    offset := int(req.GetOffset())
+    if offset < 0 {
+        return nil, status.Error(codes.InvalidArgument, "offset must not be negative")
+    }
+
+    // validate filter
+    if req.GetMinPrice() < 0 || req.GetMaxPrice() < 0 {
+        return nil, status.Error(codes.InvalidArgument, "price filter must not be negative")
+    }
+    if req.GetMaxPrice() > 0 && req.GetMinPrice() > req.GetMaxPrice() {
+        return nil, status.Error(codes.InvalidArgument, "min_price must not be greater than max_price")
+    }
+    if !repository.IsValidProductSort(req.GetSort()) {
+        return nil, status.Error(codes.InvalidArgument, "sort must be one of price_asc, price_desc, name_asc, name_desc, newest")
+    }
+    var sellerID uint64
+    if req.GetSellerId() != "" {
+        var err error
+        sellerID, err = strconv.ParseUint(req.GetSellerId(), 10, 64)
+        if err != nil {
+            return nil, status.Error(codes.InvalidArgument, "Invalid seller ID")
+        }
+    }
+
+    products, total, err := s.repo.GetAllProducts(repository.ProductFilter{
+        Limit:    limit,
+        Offset:   offset,
+        MinPrice: req.GetMinPrice(),
+        MaxPrice: req.GetMaxPrice(),
+        InStock:  req.GetInStock(),
+        Name:     req.GetName(),
+        Sort:     req.GetSort(),
+        Category: req.GetCategory(),
+        Tag:      normalizeTag(req.GetTag()),
+        SellerID: uint(sellerID),
+    })
     if err != nil {
         return nil, status.Error(codes.Internal, "Failed to retrieve products")
     }
 
     var productResponses []*pb.Product
     for _, product := range products {
-        productResponses = append(productResponses, &pb.Product{
-            Id:          strconv.FormatUint(uint64(product.ID), 10),
-            Name:        product.Name,
-            Description: product.Description,
-            Price:       int64(product.Price),
-            Stock:       int32(product.Stock),
-        })
+        productResponses = append(productResponses, toPbProduct(product))
     }
 
     response := &pb.GetAllProductResponse{
         Products: productResponses,
+        Total:    total,
+        Limit:    int32(limit),
+        Offset:   int32(offset),
+    }
+    if int64(offset+len(products)) < total {
+        response.HasNext = true
+        response.NextOffset = int32(offset + len(products))
     }
 
     return response, nil
@@ -88,12 +184,15 @@ func (s *ProductServer) GetProduct(ctx context.Context, req *pb.GetProductReques
         return nil, status.Error(codes.Internal, "Failed to retrieve product")
     }
 
-    productResponse := &pb.Product{
-        Id:          strconv.FormatUint(uint64(product.ID), 10),
-        Name:        product.Name,
-        Description: product.Description,
-        Price:       int64(product.Price),
-        Stock:       int32(product.Stock),
+    productResponse := toPbProduct(product)
+
+    // freshest catch in stock
+    catchDate, err := s.repo.GetFreshestCatchDate(product.ID)
+    if err != nil {
+        return nil, status.Error(codes.Internal, "Failed to retrieve stock lots")
+    }
+    if catchDate != nil {
+        productResponse.FreshestCatchDate = catchDate.Format(time.DateOnly)
     }
 
     return productResponse, nil
@@ -111,31 +210,38 @@ func (s *ProductServer) UpdateProduct(ctx context.Context, req *pb.UpdateProduct
         return nil, status.Error(codes.InvalidArgument, "Invalid product ID")
     }
 
-    existingProduct, err := s.repo.GetProductByID(uint(productID))
+    existingProduct, err := s.getOwnedProduct(ctx, uint(productID))
     if err != nil {
-        if err == gorm.ErrRecordNotFound {
-            return nil, status.Error(codes.NotFound, "Product not found")
-        }
-        return nil, status.Error(codes.Internal, "Failed to retrieve product")
+        return nil, err
     }
 
     existingProduct.Name = updatedProduct.GetName()
     existingProduct.Description = updatedProduct.GetDescription()
     existingProduct.Price = int64(updatedProduct.GetPrice())
-    existingProduct.Stock = int32(updatedProduct.GetStock())
+    // stock is changed by adding stock lots, not by the update
+    existingProduct.Unit = updatedProduct.GetUnit()
+    existingProduct.OrderIncrement = quantity.Quantity(updatedProduct.GetOrderIncrement())
+    existingProduct.LowStockThreshold = quantity.Quantity(updatedProduct.GetLowStockThreshold())
+    existingProduct.CategoryID, err = s.validateProductCategory(updatedProduct.GetCategoryId())
+    if err != nil {
+        return nil, err
+    }
+    existingProduct.Tags = toProductTags(updatedProduct.GetTags())
 
-    if err := s.repo.UpdateProduct(existingProduct); err != nil {
-        return nil, status.Error(codes.Internal, "Failed to update product")
+    // only admins move a product to another seller
+    if updatedProduct.GetSellerId() != "" && helper.UserRoleFromMetadata(ctx) == roleAdmin {
+        existingProduct.SellerID, err = productSeller(ctx, updatedProduct.GetSellerId())
+        if err != nil {
+            return nil, err
+        }
     }
 
-    updatedResponse := &pb.Product{
-        Id:          strconv.FormatUint(uint64(existingProduct.ID), 10),
-        Name:        existingProduct.Name,
-        Description: existingProduct.Description,
-        Price:       int64(existingProduct.Price),
-        Stock:       int32(existingProduct.Stock),
+    if err := s.repo.UpdateProduct(ctx, existingProduct); err != nil {
+        return nil, status.Error(codes.Internal, "Failed to update product")
     }
 
+    updatedResponse := toPbProduct(existingProduct)
+
     return updatedResponse, nil
 }
 
@@ -147,25 +253,161 @@ func (s *ProductServer) DeleteProduct(ctx context.Context, req *pb.DeleteProduct
         return nil, status.Error(codes.InvalidArgument, "Invalid product ID")
     }
 
-    existingProduct, err := s.repo.GetProductByID(uint(id))
+    existingProduct, err := s.getOwnedProduct(ctx, uint(id))
     if err != nil {
+        return nil, err
+    }
+
+    if err := s.repo.DeleteProductByID(uint(id)); err != nil {
+        if errors.Is(err, repository.ErrProductInUse) {
+            return nil, status.Error(codes.FailedPrecondition, err.Error())
+        }
+        return nil, status.Error(codes.Internal, "Failed to delete product")
+    }
+
+    deletedResponse := toPbProduct(existingProduct)
+
+    return deletedResponse, nil
+}
+
+// toPbProduct converts a product with its tags to the gRPC message
+func toPbProduct(product *model.Product) *pb.Product {
+    pbProduct := &pb.Product{
+        Id:                strconv.FormatUint(uint64(product.ID), 10),
+        Name:              product.Name,
+        Description:       product.Description,
+        Price:             int64(product.Price),
+        Stock:             int64(product.Stock),
+        Unit:              product.Unit,
+        OrderIncrement:    int64(product.OrderIncrement),
+        LowStockThreshold: int64(product.LowStockThreshold),
+        RatingAverage:     product.RatingAverage,
+        ReviewCount:       product.ReviewCount,
+    }
+    if product.CategoryID != nil {
+        pbProduct.CategoryId = strconv.FormatUint(uint64(*product.CategoryID), 10)
+    }
+    if product.SellerID != nil {
+        pbProduct.SellerId = strconv.FormatUint(uint64(*product.SellerID), 10)
+    }
+    for _, tag := range product.Tags {
+        pbProduct.Tags = append(pbProduct.Tags, tag.Tag)
+    }
+    for _, image := range product.Images {
+        pbProduct.ImageUrls = append(pbProduct.ImageUrls, image.URL)
+        pbProduct.ThumbnailUrls = append(pbProduct.ThumbnailUrls, image.ThumbnailURL)
+    }
+    return pbProduct
+}
+
+// tags are stored trimmed and lower case so filtering does not depend on spelling
+func normalizeTag(tag string) string {
+    return strings.ToLower(strings.TrimSpace(tag))
+}
+
+func toProductTags(tags []string) []model.ProductTag {
+    seen := map[string]bool{}
+    var productTags []model.ProductTag
+    for _, tag := range tags {
+        tag = normalizeTag(tag)
+        if tag == "" || seen[tag] {
+            continue
+        }
+        seen[tag] = true
+        productTags = append(productTags, model.ProductTag{Tag: tag})
+    }
+    return productTags
+}
+
+// validateProductCategory parses the category id of a product, an empty id means no category
+func (s *ProductServer) validateProductCategory(categoryID string) (*uint, error) {
+    if categoryID == "" {
+        return nil, nil
+    }
+
+    id, err := strconv.ParseUint(categoryID, 10, 64)
+    if err != nil {
+        return nil, status.Error(codes.InvalidArgument, "Invalid category ID")
+    }
+
+    if _, err := s.repo.GetCategoryByID(uint(id)); err != nil {
+        if err == gorm.ErrRecordNotFound {
+            return nil, status.Error(codes.InvalidArgument, "Category not found")
+        }
+        return nil, status.Error(codes.Internal, "Failed to retrieve category")
+    }
+
+    categoryIDUint := uint(id)
+    return &categoryIDUint, nil
+}
+
+func (s *ProductServer) AddProductImage(ctx context.Context, req *pb.AddProductImageRequest) (*pb.Product, error) {
+    productID, err := strconv.ParseUint(req.GetProductId(), 10, 64)
+    if err != nil {
+        return nil, status.Error(codes.InvalidArgument, "Invalid product ID")
+    }
+    if req.GetUrl() == "" || req.GetThumbnailUrl() == "" {
+        return nil, status.Error(codes.InvalidArgument, "Invalid product image data")
+    }
+
+    if _, err := s.getOwnedProduct(ctx, uint(productID)); err != nil {
+        return nil, err
+    }
+
+    image := &model.ProductImage{
+        ProductID:    uint(productID),
+        URL:          req.GetUrl(),
+        ThumbnailURL: req.GetThumbnailUrl(),
+    }
+    if err := s.repo.AddProductImage(image); err != nil {
         if err == gorm.ErrRecordNotFound {
             return nil, status.Error(codes.NotFound, "Product not found")
         }
+        return nil, status.Error(codes.Internal, "Failed to add product image")
+    }
+
+    product, err := s.repo.GetProductByID(uint(productID))
+    if err != nil {
         return nil, status.Error(codes.Internal, "Failed to retrieve product")
     }
 
-    if err := s.repo.DeleteProductByID(uint(id)); err != nil {
-        return nil, status.Error(codes.Internal, "Failed to delete product")
+    return toPbProduct(product), nil
+}
+
+// getOwnedProduct retrieves a product the caller is allowed to change
+func (s *ProductServer) getOwnedProduct(ctx context.Context, id uint) (*model.Product, error) {
+    product, err := s.repo.GetProductByID(id)
+    if err != nil {
+        if err == gorm.ErrRecordNotFound {
+            return nil, status.Error(codes.NotFound, "Product not found")
+        }
+        return nil, status.Error(codes.Internal, "Failed to retrieve product")
     }
 
-    deletedResponse := &pb.Product{
-        Id:          strconv.FormatUint(uint64(existingProduct.ID), 10),
-        Name:        existingProduct.Name,
-        Description: existingProduct.Description,
-        Price:       int64(existingProduct.Price),
-        Stock:       int32(existingProduct.Stock),
+    if err := authorizeProduct(ctx, product); err != nil {
+        return nil, err
     }
+    return product, nil
+}
 
-    return deletedResponse, nil
-}
\ No newline at end of file
+// productSeller is the seller of a new product, sellers always own what they create
+// and admins may name a seller or leave the product to the marketplace
+func productSeller(ctx context.Context, sellerID string) (*uint, error) {
+    if helper.UserRoleFromMetadata(ctx) == roleSeller {
+        callerID := helper.UserIdFromMetadata(ctx)
+        if callerID == 0 {
+            return nil, status.Error(codes.PermissionDenied, "caller is not a seller")
+        }
+        return &callerID, nil
+    }
+
+    if sellerID == "" {
+        return nil, nil
+    }
+    id, err := strconv.ParseUint(sellerID, 10, 64)
+    if err != nil {
+        return nil, status.Error(codes.InvalidArgument, "Invalid seller ID")
+    }
+    sellerIDUint := uint(id)
+    return &sellerIDUint, nil
+}
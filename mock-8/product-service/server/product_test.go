@@ -0,0 +1,33 @@
+package server
+
+import (
+    "testing"
+
+    pb "final_project-ftgo-h8/pb"
+
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+)
+
+func TestValidateProduct(t *testing.T) {
+    tests := []struct {
+        name    string
+        product *pb.Product
+        want    codes.Code
+    }{
+        {"valid", &pb.Product{Name: "Ikan Tuna", Price: 850000, Unit: "kg"}, codes.OK},
+        {"opening stock without a lot", &pb.Product{Name: "Ikan Tuna", Price: 850000, Unit: "kg", Stock: 5000}, codes.InvalidArgument},
+        {"negative stock", &pb.Product{Name: "Ikan Tuna", Price: 850000, Unit: "kg", Stock: -1}, codes.InvalidArgument},
+        {"line break in name", &pb.Product{Name: "Ikan\r\nBcc: x@example.com", Price: 850000}, codes.InvalidArgument},
+        {"missing price", &pb.Product{Name: "Ikan Tuna"}, codes.InvalidArgument},
+    }
+
+    s := &ProductServer{}
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if code := status.Code(s.ValidateProduct(tt.product)); code != tt.want {
+                t.Errorf("code = %v, want %v", code, tt.want)
+            }
+        })
+    }
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+    "context"
+    "errors"
+    "log"
+    "strconv"
+    "time"
+
+    pb "final_project-ftgo-h8/pb"
+    "final_project-ftgo-h8/product-service/model"
+    "final_project-ftgo-h8/product-service/repository"
+    "final_project-ftgo-h8/quantity"
+
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+    "gorm.io/gorm"
+)
+
+const (
+    defaultReservationTTL = 10 * time.Minute
+    maxReservationTTL     = time.Hour
+)
+
+func (s *ProductServer) ReserveStock(ctx context.Context, req *pb.ReserveStockRequest) (*pb.Reservation, error) {
+    if req.GetReservationId() == "" || len(req.GetItems()) == 0 {
+        return nil, status.Error(codes.InvalidArgument, "reservation_id and items are required")
+    }
+
+    ttl := time.Duration(req.GetTtlSeconds()) * time.Second
+    if ttl == 0 {
+        ttl = defaultReservationTTL
+    }
+    if ttl < 0 || ttl > maxReservationTTL {
+        return nil, status.Errorf(codes.InvalidArgument, "ttl_seconds must be between 1 and %d", int(maxReservationTTL.Seconds()))
+    }
+
+    // merge lines of the same product
+    quantities := map[uint]quantity.Quantity{}
+    for _, item := range req.GetItems() {
+        productID, err := strconv.ParseUint(item.GetProductId(), 10, 64)
+        if err != nil {
+            return nil, status.Error(codes.InvalidArgument, "Invalid product ID")
+        }
+        if item.GetQuantity() <= 0 || quantity.Quantity(item.GetQuantity()) > quantity.Max {
+            return nil, status.Error(codes.InvalidArgument, "quantity must be positive and not more than "+quantity.Max.String())
+        }
+        quantities[uint(productID)] += quantity.Quantity(item.GetQuantity())
+        if quantities[uint(productID)] > quantity.Max {
+            return nil, status.Error(codes.InvalidArgument, quantity.ErrTooLarge.Error())
+        }
+    }
+
+    var items []model.StockReservationItem
+    for productID, q := range quantities {
+        items = append(items, model.StockReservationItem{ProductID: productID, Quantity: q})
+    }
+
+    reservation, err := s.repo.ReserveStock(ctx, req.GetReservationId(), items, time.Now().Add(ttl))
+    if err != nil {
+        return nil, reservationError(err, "Failed to reserve stock")
+    }
+
+    return toPbReservation(reservation), nil
+}
+
+func (s *ProductServer) CommitReservation(ctx context.Context, req *pb.CommitReservationRequest) (*pb.Reservation, error) {
+    reservation, err := s.repo.CommitReservation(req.GetReservationId())
+    if err != nil {
+        return nil, reservationError(err, "Failed to commit reservation")
+    }
+
+    return toPbReservation(reservation), nil
+}
+
+func (s *ProductServer) ReleaseReservation(ctx context.Context, req *pb.ReleaseReservationRequest) (*pb.Reservation, error) {
+    reservation, err := s.repo.ReleaseReservation(ctx, req.GetReservationId())
+    if err != nil {
+        return nil, reservationError(err, "Failed to release reservation")
+    }
+
+    return toPbReservation(reservation), nil
+}
+
+// CancelReservation returns the stock of a reserved or committed reservation, its order is cancelled.
+func (s *ProductServer) CancelReservation(ctx context.Context, req *pb.CancelReservationRequest) (*pb.Reservation, error) {
+    reservation, err := s.repo.CancelReservation(ctx, req.GetReservationId())
+    if err != nil {
+        return nil, reservationError(err, "Failed to cancel reservation")
+    }
+
+    return toPbReservation(reservation), nil
+}
+
+// ReleaseExpiredReservations returns the stock of reservations past their ttl every interval until ctx is done.
+func (s *ProductServer) ReleaseExpiredReservations(ctx context.Context, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case now := <-ticker.C:
+            released, err := s.repo.ReleaseExpiredReservations(now)
+            if err != nil {
+                log.Printf("Failed to release expired reservations: %v", err)
+                continue
+            }
+            if released > 0 {
+                log.Printf("Released %d expired reservations", released)
+            }
+        }
+    }
+}
+
+func reservationError(err error, message string) error {
+    switch {
+    case errors.Is(err, gorm.ErrRecordNotFound):
+        return status.Error(codes.NotFound, "Product or reservation not found")
+    case errors.Is(err, repository.ErrQuantityIncrement):
+        return status.Error(codes.InvalidArgument, err.Error())
+    case errors.Is(err, repository.ErrInsufficientStock):
+        return status.Error(codes.FailedPrecondition, "Product stock is unavailable")
+    case errors.Is(err, repository.ErrReservationClosed):
+        return status.Error(codes.FailedPrecondition, "Reservation is already released")
+    case errors.Is(err, repository.ErrReservationCommitted):
+        return status.Error(codes.FailedPrecondition, "Reservation is already committed, cancel its order instead")
+    default:
+        return status.Error(codes.Internal, message)
+    }
+}
+
+func toPbReservation(reservation *model.StockReservation) *pb.Reservation {
+    var items []*pb.StockItem
+    for _, item := range reservation.Items {
+        items = append(items, &pb.StockItem{
+            ProductId: strconv.FormatUint(uint64(item.ProductID), 10),
+            Quantity:  int64(item.Quantity),
+            Name:      item.Name,
+            Price:     item.Price,
+            Unit:      item.Unit,
+        })
+    }
+
+    return &pb.Reservation{
+        Id:        reservation.ID,
+        Status:    reservation.Status,
+        Items:     items,
+        ExpiresAt: reservation.ExpiresAt.Unix(),
+    }
+}
@@ -0,0 +1,163 @@
+package server
+
+import (
+    "context"
+    "strconv"
+    "strings"
+    "time"
+    "unicode/utf8"
+
+    pb "final_project-ftgo-h8/pb"
+    "final_project-ftgo-h8/product-service/model"
+    "final_project-ftgo-h8/product-service/repository"
+
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+    "gorm.io/gorm"
+)
+
+const maxReviewBodyLength = 2000
+
+func toPbReview(review *model.Review) *pb.Review {
+    return &pb.Review{
+        Id:            strconv.FormatUint(uint64(review.ID), 10),
+        ProductId:     strconv.FormatUint(uint64(review.ProductID), 10),
+        UserId:        strconv.FormatUint(uint64(review.UserID), 10),
+        OrderDetailId: strconv.FormatUint(uint64(review.OrderDetailID), 10),
+        Rating:        int32(review.Rating),
+        Body:          review.Body,
+        Hidden:        review.Hidden,
+        CreatedAt:     review.CreatedAt.Format(time.RFC3339),
+    }
+}
+
+// CreateReview stores a review, the api gateway checks that the user bought and received the order line
+func (s *ProductServer) CreateReview(ctx context.Context, req *pb.CreateReviewRequest) (*pb.Review, error) {
+    newReview := req.GetReview()
+
+    productID, err := strconv.ParseUint(newReview.GetProductId(), 10, 64)
+    if err != nil {
+        return nil, status.Error(codes.InvalidArgument, "Invalid product ID")
+    }
+    userID, err := strconv.ParseUint(newReview.GetUserId(), 10, 64)
+    if err != nil {
+        return nil, status.Error(codes.InvalidArgument, "Invalid user ID")
+    }
+    orderDetailID, err := strconv.ParseUint(newReview.GetOrderDetailId(), 10, 64)
+    if err != nil {
+        return nil, status.Error(codes.InvalidArgument, "Invalid order detail ID")
+    }
+
+    // validate review
+    if newReview.GetRating() < 1 || newReview.GetRating() > 5 {
+        return nil, status.Error(codes.InvalidArgument, "rating must be between 1 and 5")
+    }
+    body := strings.TrimSpace(newReview.GetBody())
+    if body == "" {
+        return nil, status.Error(codes.InvalidArgument, "body is required")
+    }
+    if utf8.RuneCountInString(body) > maxReviewBodyLength {
+        return nil, status.Errorf(codes.InvalidArgument, "body must not be longer than %d characters", maxReviewBodyLength)
+    }
+
+    review := &model.Review{
+        ProductID:     uint(productID),
+        UserID:        uint(userID),
+        OrderDetailID: uint(orderDetailID),
+        Rating:        int(newReview.GetRating()),
+        Body:          body,
+    }
+    if err := s.repo.CreateReview(review); err != nil {
+        if err == gorm.ErrRecordNotFound {
+            return nil, status.Error(codes.NotFound, "Product not found")
+        }
+        if err == repository.ErrReviewExists {
+            return nil, status.Error(codes.AlreadyExists, err.Error())
+        }
+        return nil, status.Error(codes.Internal, "Failed to create review")
+    }
+
+    return toPbReview(review), nil
+}
+
+func (s *ProductServer) GetAllReview(ctx context.Context, req *pb.GetAllReviewRequest) (*pb.GetAllReviewResponse, error) {
+    productID, err := strconv.ParseUint(req.GetProductId(), 10, 64)
+    if err != nil {
+        return nil, status.Error(codes.InvalidArgument, "Invalid product ID")
+    }
+
+    // validate page
+    limit := int(req.GetLimit())
+    if limit == 0 {
+        limit = defaultProductLimit
+    }
+    if limit < 0 || limit > maxProductLimit {
+        return nil, status.Errorf(codes.InvalidArgument, "limit must be between 1 and %d", maxProductLimit)
+    }
+    offset := int(req.GetOffset())
+    if offset < 0 {
+        return nil, status.Error(codes.InvalidArgument, "offset must not be negative")
+    }
+
+    // product must exist
+    if _, err := s.repo.GetProductByID(uint(productID)); err != nil {
+        if err == gorm.ErrRecordNotFound {
+            return nil, status.Error(codes.NotFound, "Product not found")
+        }
+        return nil, status.Error(codes.Internal, "Failed to retrieve product")
+    }
+
+    reviews, total, err := s.repo.GetReviews(uint(productID), limit, offset)
+    if err != nil {
+        return nil, status.Error(codes.Internal, "Failed to retrieve reviews")
+    }
+
+    response := &pb.GetAllReviewResponse{
+        Total:  total,
+        Limit:  int32(limit),
+        Offset: int32(offset),
+    }
+    for _, review := range reviews {
+        response.Reviews = append(response.Reviews, toPbReview(review))
+    }
+    if int64(offset+len(reviews)) < total {
+        response.HasNext = true
+        response.NextOffset = int32(offset + len(reviews))
+    }
+
+    return response, nil
+}
+
+func (s *ProductServer) HideReview(ctx context.Context, req *pb.HideReviewRequest) (*pb.Review, error) {
+    id, err := strconv.ParseUint(req.GetId(), 10, 64)
+    if err != nil {
+        return nil, status.Error(codes.InvalidArgument, "Invalid review ID")
+    }
+
+    review, err := s.repo.SetReviewHidden(uint(id), req.GetHidden())
+    if err != nil {
+        if err == gorm.ErrRecordNotFound {
+            return nil, status.Error(codes.NotFound, "Review not found")
+        }
+        return nil, status.Error(codes.Internal, "Failed to update review")
+    }
+
+    return toPbReview(review), nil
+}
+
+func (s *ProductServer) DeleteReview(ctx context.Context, req *pb.DeleteReviewRequest) (*pb.Review, error) {
+    id, err := strconv.ParseUint(req.GetId(), 10, 64)
+    if err != nil {
+        return nil, status.Error(codes.InvalidArgument, "Invalid review ID")
+    }
+
+    review, err := s.repo.DeleteReviewByID(uint(id))
+    if err != nil {
+        if err == gorm.ErrRecordNotFound {
+            return nil, status.Error(codes.NotFound, "Review not found")
+        }
+        return nil, status.Error(codes.Internal, "Failed to delete review")
+    }
+
+    return toPbReview(review), nil
+}
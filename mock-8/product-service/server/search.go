@@ -0,0 +1,62 @@
+package server
+
+import (
+    "context"
+    "strings"
+    "unicode/utf8"
+
+    pb "final_project-ftgo-h8/pb"
+
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+)
+
+const maxSearchQueryLength = 100
+
+func (s *ProductServer) SearchProducts(ctx context.Context, req *pb.SearchProductsRequest) (*pb.SearchProductsResponse, error) {
+    // validate query
+    q := strings.TrimSpace(req.GetQ())
+    if q == "" {
+        return nil, status.Error(codes.InvalidArgument, "q is required")
+    }
+    if utf8.RuneCountInString(q) > maxSearchQueryLength {
+        return nil, status.Errorf(codes.InvalidArgument, "q must not be longer than %d characters", maxSearchQueryLength)
+    }
+
+    // validate page
+    limit := int(req.GetLimit())
+    if limit == 0 {
+        limit = defaultProductLimit
+    }
+    if limit < 0 || limit > maxProductLimit {
+        return nil, status.Errorf(codes.InvalidArgument, "limit must be between 1 and %d", maxProductLimit)
+    }
+    offset := int(req.GetOffset())
+    if offset < 0 {
+        return nil, status.Error(codes.InvalidArgument, "offset must not be negative")
+    }
+
+    results, total, err := s.repo.SearchProducts(q, limit, offset)
+    if err != nil {
+        return nil, status.Error(codes.Internal, "Failed to search products")
+    }
+
+    response := &pb.SearchProductsResponse{
+        Total:  total,
+        Limit:  int32(limit),
+        Offset: int32(offset),
+    }
+    for _, result := range results {
+        response.Results = append(response.Results, &pb.SearchResult{
+            Product: toPbProduct(result.Product),
+            Rank:    result.Rank,
+            Snippet: result.Snippet,
+        })
+    }
+    if int64(offset+len(results)) < total {
+        response.HasNext = true
+        response.NextOffset = int32(offset + len(results))
+    }
+
+    return response, nil
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+    "context"
+    "strconv"
+    "time"
+
+    "final_project-ftgo-h8/helper"
+    pb "final_project-ftgo-h8/pb"
+    "final_project-ftgo-h8/product-service/model"
+    "final_project-ftgo-h8/product-service/repository"
+
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+    "gorm.io/gorm"
+)
+
+func toPbRestockSubscription(subscription *model.RestockSubscription) *pb.RestockSubscription {
+    return &pb.RestockSubscription{
+        ProductId: strconv.FormatUint(uint64(subscription.ProductID), 10),
+        UserId:    strconv.FormatUint(uint64(subscription.UserID), 10),
+        CreatedAt: subscription.CreatedAt.Format(time.RFC3339),
+    }
+}
+
+// parseRestockSubscription reads the product of the request and the calling user
+func parseRestockSubscription(ctx context.Context, req *pb.RestockSubscriptionRequest) (uint, uint, error) {
+    productID, err := strconv.ParseUint(req.GetProductId(), 10, 64)
+    if err != nil {
+        return 0, 0, status.Error(codes.InvalidArgument, "Invalid product ID")
+    }
+
+    userID := helper.UserIdFromMetadata(ctx)
+    if userID == 0 {
+        return 0, 0, status.Error(codes.Unauthenticated, "caller is not a user")
+    }
+    return uint(productID), userID, nil
+}
+
+// SubscribeRestock asks for a back in stock email for an out of stock product
+func (s *ProductServer) SubscribeRestock(ctx context.Context, req *pb.RestockSubscriptionRequest) (*pb.RestockSubscription, error) {
+    productID, userID, err := parseRestockSubscription(ctx, req)
+    if err != nil {
+        return nil, err
+    }
+
+    subscription, err := s.repo.SubscribeRestock(productID, userID)
+    if err != nil {
+        if err == gorm.ErrRecordNotFound {
+            return nil, status.Error(codes.NotFound, "Product not found")
+        }
+        if err == repository.ErrProductInStock {
+            return nil, status.Error(codes.FailedPrecondition, err.Error())
+        }
+        return nil, status.Error(codes.Internal, "Failed to subscribe to restock")
+    }
+
+    return toPbRestockSubscription(subscription), nil
+}
+
+func (s *ProductServer) UnsubscribeRestock(ctx context.Context, req *pb.RestockSubscriptionRequest) (*pb.RestockSubscription, error) {
+    productID, userID, err := parseRestockSubscription(ctx, req)
+    if err != nil {
+        return nil, err
+    }
+
+    if err := s.repo.UnsubscribeRestock(productID, userID); err != nil {
+        if err == gorm.ErrRecordNotFound {
+            return nil, status.Error(codes.NotFound, "Restock subscription not found")
+        }
+        return nil, status.Error(codes.Internal, "Failed to unsubscribe from restock")
+    }
+
+    return toPbRestockSubscription(&model.RestockSubscription{ProductID: productID, UserID: userID}), nil
+}
@@ -0,0 +1,121 @@
+package quantity
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Scale is the number of stored units in one whole unit, quantities keep three decimals.
+const Scale = 1000
+
+// Max is the largest quantity of one order line, a million whole units.
+const Max = Quantity(1000000 * Scale)
+
+// Quantity is a fixed-point amount of a product unit stored in thousandths,
+// 1.5 kg is stored as 1500.
+type Quantity int64
+
+var (
+	ErrInvalidQuantity = errors.New("quantity must be a number with at most 3 decimals")
+	ErrTooLarge        = fmt.Errorf("quantity must not be more than %s", Max)
+	ErrTotalOverflow   = errors.New("total price is too large")
+)
+
+// FromInt returns whole units as a quantity.
+func FromInt(n int64) Quantity {
+	return Quantity(n * Scale)
+}
+
+// Parse reads a decimal number such as "2", "0.25" or "1.5".
+func Parse(s string) (Quantity, error) {
+	s = strings.TrimSpace(s)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" || len(frac) > 3 || strings.ContainsAny(whole+frac, "+-eE") {
+		return 0, ErrInvalidQuantity
+	}
+	if whole == "" {
+		whole = "0"
+	}
+
+	w, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidQuantity
+	}
+	if w > int64(Max)/Scale {
+		return 0, ErrTooLarge
+	}
+	var f int64
+	if frac != "" {
+		f, err = strconv.ParseInt((frac + "00")[:3], 10, 64)
+		if err != nil {
+			return 0, ErrInvalidQuantity
+		}
+	}
+
+	q := Quantity(w*Scale + f)
+	if q > Max {
+		return 0, ErrTooLarge
+	}
+	if negative {
+		q = -q
+	}
+	return q, nil
+}
+
+// String formats the quantity without trailing zeros, 1500 becomes "1.5".
+func (q Quantity) String() string {
+	sign := ""
+	if q < 0 {
+		sign = "-"
+		q = -q
+	}
+
+	whole, frac := int64(q)/Scale, int64(q)%Scale
+	if frac == 0 {
+		return fmt.Sprintf("%s%d", sign, whole)
+	}
+	return fmt.Sprintf("%s%d.%s", sign, whole, strings.TrimRight(fmt.Sprintf("%03d", frac), "0"))
+}
+
+// MarshalJSON writes the quantity as a json number.
+func (q Quantity) MarshalJSON() ([]byte, error) {
+	return []byte(q.String()), nil
+}
+
+// UnmarshalJSON reads a json number or numeric string without rounding.
+func (q *Quantity) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "null" {
+		return nil
+	}
+
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*q = v
+	return nil
+}
+
+// IsMultipleOf reports whether q can be ordered in steps of increment.
+func (q Quantity) IsMultipleOf(increment Quantity) bool {
+	return increment > 0 && q%increment == 0
+}
+
+// Total returns the price of q when price is the price of one whole unit, rounded half up.
+// It fails instead of overflowing, q and price must not be negative.
+func (q Quantity) Total(price int64) (int64, error) {
+	if q < 0 || price < 0 {
+		return 0, ErrTotalOverflow
+	}
+	if q > 0 && price > (math.MaxInt64-Scale/2)/int64(q) {
+		return 0, ErrTotalOverflow
+	}
+	return (price*int64(q) + Scale/2) / Scale, nil
+}
@@ -0,0 +1,156 @@
+package quantity
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Quantity
+		err  error
+	}{
+		{"2", 2000, nil},
+		{"0.25", 250, nil},
+		{"1.5", 1500, nil},
+		{"0.001", 1, nil},
+		{".5", 500, nil},
+		{" 3 ", 3000, nil},
+		{"-1.5", -1500, nil},
+		{"1000000", Max, nil},
+		{"1000000.001", 0, ErrTooLarge},
+		{"1000001", 0, ErrTooLarge},
+		{"1.2345", 0, ErrInvalidQuantity},
+		{"", 0, ErrInvalidQuantity},
+		{".", 0, ErrInvalidQuantity},
+		{"abc", 0, ErrInvalidQuantity},
+		{"1e3", 0, ErrInvalidQuantity},
+		{"+1", 0, ErrInvalidQuantity},
+		{"--1", 0, ErrInvalidQuantity},
+		{"1.-5", 0, ErrInvalidQuantity},
+		{"99999999999999999999", 0, ErrInvalidQuantity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Parse(%q) err = %v, want %v", tt.in, err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		q    Quantity
+		want string
+	}{
+		{0, "0"},
+		{1, "0.001"},
+		{250, "0.25"},
+		{1000, "1"},
+		{1500, "1.5"},
+		{-1500, "-1.5"},
+		{Max, "1000000"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.q.String(); got != tt.want {
+			t.Errorf("Quantity(%d).String() = %q, want %q", int64(tt.q), got, tt.want)
+		}
+	}
+}
+
+func TestTotal(t *testing.T) {
+	tests := []struct {
+		name  string
+		q     Quantity
+		price int64
+		want  int64
+		err   error
+	}{
+		{"whole units", FromInt(3), 10000, 30000, nil},
+		{"half a unit", 500, 10000, 5000, nil},
+		{"rounds down below half", 1, 499, 0, nil},
+		{"rounds half up", 1, 500, 1, nil},
+		{"rounds up above half", 1, 1501, 2, nil},
+		{"zero quantity", 0, math.MaxInt64, 0, nil},
+		{"overflow", Max, math.MaxInt64, 0, ErrTotalOverflow},
+		{"negative quantity", -1000, 100, 0, ErrTotalOverflow},
+		{"negative price", 1000, -100, 0, ErrTotalOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.q.Total(tt.price)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("Total = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsMultipleOfUnitIncrement(t *testing.T) {
+	tests := []struct {
+		name string
+		q    Quantity
+		unit string
+		want bool
+	}{
+		{"two pieces", FromInt(2), UnitPiece, true},
+		{"half a piece", 500, UnitPiece, false},
+		{"one and a half kg", 1500, UnitKg, true},
+		{"kg below 100 g steps", 1550, UnitKg, false},
+		{"250 gram", FromInt(250), UnitGram, false},
+		{"300 gram", FromInt(300), UnitGram, true},
+		{"one pack", FromInt(1), UnitPack, true},
+		{"unknown unit", FromInt(1), "box", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.q.IsMultipleOf(DefaultIncrement(tt.unit)); got != tt.want {
+				t.Errorf("%s IsMultipleOf(%s) = %v, want %v", tt.q, DefaultIncrement(tt.unit), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	type line struct {
+		Quantity Quantity `json:"quantity"`
+	}
+
+	for _, q := range []Quantity{0, 1, 250, 1500, FromInt(42), Max} {
+		b, err := json.Marshal(line{q})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got line
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatalf("unmarshal %s: %v", b, err)
+		}
+		if got.Quantity != q {
+			t.Errorf("%s round trips to %d, want %d", b, got.Quantity, q)
+		}
+	}
+
+	var got line
+	if err := json.Unmarshal([]byte(`{"quantity":"0.25"}`), &got); err != nil || got.Quantity != 250 {
+		t.Errorf("string quantity = %d, %v, want 250", got.Quantity, err)
+	}
+	if err := json.Unmarshal([]byte(`{"quantity":1.2345}`), &got); !errors.Is(err, ErrInvalidQuantity) {
+		t.Errorf("err = %v, want %v", err, ErrInvalidQuantity)
+	}
+}
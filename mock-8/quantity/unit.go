@@ -0,0 +1,32 @@
+package quantity
+
+// unit of measure a product is sold and stocked in
+const (
+	UnitPiece = "piece"
+	UnitKg    = "kg"
+	UnitGram  = "gram"
+	UnitPack  = "pack"
+)
+
+// smallest order step of each unit when the product does not declare one
+var defaultIncrements = map[string]Quantity{
+	UnitPiece: FromInt(1),
+	UnitKg:    100,
+	UnitGram:  FromInt(100),
+	UnitPack:  FromInt(1),
+}
+
+func IsValidUnit(unit string) bool {
+	_, ok := defaultIncrements[unit]
+	return ok
+}
+
+// DefaultIncrement returns the default order increment of unit.
+func DefaultIncrement(unit string) Quantity {
+	return defaultIncrements[unit]
+}
+
+// IsWholeUnit reports whether unit is counted and can not be split.
+func IsWholeUnit(unit string) bool {
+	return unit == UnitPiece || unit == UnitPack
+}
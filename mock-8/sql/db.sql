@@ -7,8 +7,21 @@ CREATE TABLE Users (
     phone VARCHAR(20),
     status VARCHAR(20),
     role VARCHAR(20),
-    amount FLOAT,
-    registered_at TIMESTAMP
+    amount BIGINT,
+    registered_at TIMESTAMP,
+    password_changed_at TIMESTAMP
+);
+
+-- store of a user with the Seller role
+CREATE TABLE seller_profiles (
+    user_id INT PRIMARY KEY,
+    store_name VARCHAR(100) NOT NULL,
+    location VARCHAR(100) NOT NULL,
+    bank_name VARCHAR(50) NOT NULL,
+    bank_account_number VARCHAR(30) NOT NULL,
+    bank_account_name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES Users(id)
 );
 
 CREATE TABLE User_verifications (
@@ -18,36 +31,276 @@ CREATE TABLE User_verifications (
     FOREIGN KEY (user_id) REFERENCES Users(id)
 );
 
+CREATE TABLE refresh_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    family_id VARCHAR(64) NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX refresh_tokens_family_id_idx ON refresh_tokens (family_id);
+
+CREATE TABLE password_resets (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE TABLE categories (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    slug VARCHAR(100) NOT NULL UNIQUE,
+    parent_id INT,
+    FOREIGN KEY (parent_id) REFERENCES categories(id)
+);
+
 CREATE TABLE products (
     id SERIAL PRIMARY KEY,
     name VARCHAR(100),
     description VARCHAR(200),
-    price FLOAT,
-    stock INT
+    price BIGINT,
+    -- stock and quantities are fixed-point thousandths of the unit, 1.5 kg is 1500
+    stock BIGINT,
+    unit VARCHAR(10) NOT NULL DEFAULT 'piece',
+    order_increment BIGINT NOT NULL DEFAULT 1000,
+    -- stock below it alerts the seller or admins, 0 turns alerts off
+    low_stock_threshold BIGINT NOT NULL DEFAULT 0,
+    category_id INT,
+    -- visible review ratings, recomputed whenever a review is added, hidden or removed
+    rating_average NUMERIC(3, 2) NOT NULL DEFAULT 0,
+    review_count INT NOT NULL DEFAULT 0,
+    -- owning seller, NULL for products sold by the marketplace
+    seller_id INT,
+    FOREIGN KEY (category_id) REFERENCES categories(id),
+    FOREIGN KEY (seller_id) REFERENCES users(id)
+);
+
+CREATE INDEX products_seller_id_idx ON products (seller_id);
+
+-- product search, indonesian stemming over name (weight A) and description (weight B)
+-- and trigram similarity on name for misspelled queries
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE products ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('indonesian', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('indonesian', coalesce(description, '')), 'B')
+) STORED;
+
+CREATE INDEX products_search_vector_idx ON products USING GIN (search_vector);
+CREATE INDEX products_name_trgm_idx ON products USING GIN (name gin_trgm_ops);
+
+-- buyers waiting for an out of stock product, removed once they are emailed
+CREATE TABLE restock_subscriptions (
+    product_id INT NOT NULL,
+    user_id INT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (product_id, user_id),
+    FOREIGN KEY (product_id) REFERENCES products(id),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE TABLE product_tags (
+    product_id INT NOT NULL,
+    tag VARCHAR(50) NOT NULL,
+    PRIMARY KEY (product_id, tag),
+    FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE INDEX product_tags_tag_idx ON product_tags (tag);
+
+CREATE TABLE product_images (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL,
+    position INT NOT NULL,
+    url VARCHAR(300) NOT NULL,
+    thumbnail_url VARCHAR(300) NOT NULL,
+    FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE INDEX product_images_product_id_position_idx ON product_images (product_id, position);
+
+CREATE TABLE stock_reservations (
+    id VARCHAR(64) PRIMARY KEY,
+    status VARCHAR(20) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX stock_reservations_status_expires_at_idx ON stock_reservations (status, expires_at);
+
+CREATE TABLE stock_reservation_items (
+    id SERIAL PRIMARY KEY,
+    reservation_id VARCHAR(64) NOT NULL,
+    product_id INT NOT NULL,
+    name VARCHAR(100),
+    price BIGINT NOT NULL,
+    quantity BIGINT NOT NULL,
+    unit VARCHAR(10) NOT NULL DEFAULT 'piece',
+    FOREIGN KEY (reservation_id) REFERENCES stock_reservations(id),
+    FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE TABLE stock_lots (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL,
+    lot_number VARCHAR(50) NOT NULL,
+    catch_date DATE NOT NULL,
+    origin_port VARCHAR(100),
+    supplier VARCHAR(100),
+    best_before DATE NOT NULL,
+    quantity BIGINT NOT NULL,
+    remaining BIGINT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE INDEX stock_lots_product_id_best_before_idx ON stock_lots (product_id, best_before) WHERE status = 'available';
+
+CREATE TABLE stock_reservation_lots (
+    id SERIAL PRIMARY KEY,
+    reservation_item_id INT NOT NULL,
+    lot_id INT NOT NULL,
+    quantity BIGINT NOT NULL,
+    FOREIGN KEY (reservation_item_id) REFERENCES stock_reservation_items(id),
+    FOREIGN KEY (lot_id) REFERENCES stock_lots(id)
 );
 
 CREATE TABLE orders (
     id SERIAL PRIMARY KEY,
     user_id INT NOT NULL,
     order_date TIMESTAMP,
-    FOREIGN KEY (user_id) REFERENCES users(id)
+    status VARCHAR(20) NOT NULL DEFAULT 'Pending',
+    total_price BIGINT NOT NULL DEFAULT 0,
+    reservation_id VARCHAR(64),
+    -- checkout creates one order per seller, NULL for marketplace products
+    seller_id INT,
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (seller_id) REFERENCES users(id)
+);
+
+CREATE INDEX orders_seller_id_status_idx ON orders (seller_id, status);
+
+CREATE TABLE order_status_logs (
+    id SERIAL PRIMARY KEY,
+    order_id INT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    changed_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (order_id) REFERENCES orders(id)
 );
 
 CREATE TABLE order_details (
     id SERIAL PRIMARY KEY,
     order_id INT NOT NULL,
     product_id INT NOT NULL,
-    quantity INT,
-    total_price FLOAT,
+    quantity BIGINT,
+    total_price BIGINT,
     FOREIGN KEY (order_id) REFERENCES orders(id),
     FOREIGN KEY (product_id) REFERENCES products(id)
 );
 
+CREATE TABLE reviews (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL,
+    user_id INT NOT NULL,
+    order_detail_id INT NOT NULL UNIQUE,
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    body VARCHAR(2000) NOT NULL,
+    hidden BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (product_id) REFERENCES products(id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (order_detail_id) REFERENCES order_details(id)
+);
+
+CREATE INDEX reviews_product_id_created_at_idx ON reviews (product_id, created_at);
+
+CREATE TABLE cart_items (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    product_id INT NOT NULL,
+    quantity BIGINT NOT NULL,
+    UNIQUE (user_id, product_id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (product_id) REFERENCES products(id)
+);
+
+CREATE TABLE wallet_transactions (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    amount BIGINT NOT NULL,
+    balance_after BIGINT NOT NULL,
+    order_id INT,
+    top_up_id VARCHAR(50),
+    note VARCHAR(200),
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (order_id) REFERENCES orders(id)
+);
+
+CREATE INDEX wallet_transactions_user_id_created_at_idx ON wallet_transactions (user_id, created_at);
+-- a top-up is credited at most once
+CREATE UNIQUE INDEX wallet_transactions_top_up_id_idx ON wallet_transactions (top_up_id) WHERE top_up_id <> '';
+
+CREATE TABLE outbox (
+    id SERIAL PRIMARY KEY,
+    queue VARCHAR(100) NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload BYTEA NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    trace_parent VARCHAR(55) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    sent_at TIMESTAMP
+);
+
+CREATE INDEX outbox_pending_idx ON outbox (id) WHERE sent_at IS NULL;
+
+-- one-off migrations in sql/ that already match this schema, they skip themselves when recorded here
+CREATE TABLE schema_migrations (
+    name VARCHAR(100) PRIMARY KEY,
+    applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO schema_migrations (name) VALUES ('quantity_thousandths');
+
 
 -- contoh record data
-INSERT INTO products (name, description, price, stock) VALUES
-    ('Ikan Salmon', 'Salmon Norwegia', 750000, 100),
-    ('Udang Windu', 'Udang windu besar-besaran dari Indonesia', 450000, 150),
-    ('Ikan Tuna', 'Tuna sirip kuning yang segar', 850000, 75),
-    ('Kerang Segar', 'Kerang segar dari pantai lokal', 120000, 200),
-    ('Kepiting Batik', 'Kepiting batik premium dari Indonesia', 500000, 50);
\ No newline at end of file
+INSERT INTO categories (name, slug, parent_id) VALUES
+    ('Seafood', 'seafood', NULL),
+    ('Fish', 'fish', 1),
+    ('Shellfish', 'shellfish', 1),
+    ('Crab', 'crab', 3),
+    ('Shrimp', 'shrimp', 3);
+
+INSERT INTO products (name, description, price, stock, unit, order_increment, low_stock_threshold, category_id) VALUES
+    ('Ikan Salmon', 'Salmon Norwegia', 750000, 100000, 'kg', 250, 10000, 2),
+    ('Udang Windu', 'Udang windu besar-besaran dari Indonesia', 450000, 150000, 'kg', 500, 15000, 5),
+    ('Ikan Tuna', 'Tuna sirip kuning yang segar', 850000, 75000, 'kg', 500, 10000, 2),
+    ('Kerang Segar', 'Kerang segar dari pantai lokal', 120000, 200000, 'pack', 1000, 20000, 3),
+    ('Kepiting Batik', 'Kepiting batik premium dari Indonesia', 500000, 50000, 'piece', 1000, 10000, 4);
+
+-- the stock of the sample products comes from one lot each
+INSERT INTO stock_lots (product_id, lot_number, catch_date, origin_port, supplier, best_before, quantity, remaining, status, created_at) VALUES
+    (1, 'SEED-1', CURRENT_DATE - 1, 'Bergen', 'Sample Supplier', CURRENT_DATE + 7, 100000, 100000, 'available', NOW()),
+    (2, 'SEED-2', CURRENT_DATE - 1, 'Muara Baru', 'Sample Supplier', CURRENT_DATE + 5, 150000, 150000, 'available', NOW()),
+    (3, 'SEED-3', CURRENT_DATE - 1, 'Bitung', 'Sample Supplier', CURRENT_DATE + 5, 75000, 75000, 'available', NOW()),
+    (4, 'SEED-4', CURRENT_DATE - 1, 'Muara Angke', 'Sample Supplier', CURRENT_DATE + 3, 200000, 200000, 'available', NOW()),
+    (5, 'SEED-5', CURRENT_DATE - 1, 'Makassar', 'Sample Supplier', CURRENT_DATE + 3, 50000, 50000, 'available', NOW());
+
+INSERT INTO product_tags (product_id, tag) VALUES
+    (1, 'imported'),
+    (2, 'local'),
+    (3, 'fresh'),
+    (4, 'local'),
+    (4, 'fresh'),
+    (5, 'premium');
\ No newline at end of file
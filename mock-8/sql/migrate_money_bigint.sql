@@ -0,0 +1,11 @@
+-- run on a database created while money was stored as FLOAT, db.sql already creates new databases with this schema.
+-- amounts are whole rupiah, running it again changes nothing
+BEGIN;
+
+ALTER TABLE users ALTER COLUMN amount TYPE BIGINT USING round(amount)::BIGINT;
+ALTER TABLE products ALTER COLUMN price TYPE BIGINT USING round(price)::BIGINT;
+ALTER TABLE orders ALTER COLUMN total_price TYPE BIGINT USING round(total_price)::BIGINT;
+ALTER TABLE orders ALTER COLUMN status SET DEFAULT 'Pending';
+ALTER TABLE order_details ALTER COLUMN total_price TYPE BIGINT USING round(total_price)::BIGINT;
+
+COMMIT;
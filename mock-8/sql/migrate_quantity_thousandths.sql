@@ -0,0 +1,43 @@
+-- run on a database created before quantities were stored in thousandths of the unit,
+-- db.sql already creates new databases with this schema.
+-- quantities are multiplied by 1000, so the run is recorded in schema_migrations and running it again changes nothing
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    name VARCHAR(100) PRIMARY KEY,
+    applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+DO $$
+BEGIN
+    -- a concurrent run waits on the marker row and then skips
+    INSERT INTO schema_migrations (name) VALUES ('quantity_thousandths') ON CONFLICT (name) DO NOTHING;
+    IF NOT FOUND THEN
+        RAISE NOTICE 'quantity_thousandths is already applied';
+        RETURN;
+    END IF;
+
+    -- databases migrated before the marker existed already have a BIGINT stock
+    IF (SELECT data_type FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'products' AND column_name = 'stock') = 'bigint' THEN
+        RAISE NOTICE 'products.stock is already BIGINT, quantity_thousandths is only recorded';
+        RETURN;
+    END IF;
+
+    ALTER TABLE products ALTER COLUMN stock TYPE BIGINT;
+    ALTER TABLE products ADD COLUMN IF NOT EXISTS unit VARCHAR(10) NOT NULL DEFAULT 'piece';
+    ALTER TABLE products ADD COLUMN IF NOT EXISTS order_increment BIGINT NOT NULL DEFAULT 1000;
+    UPDATE products SET stock = stock * 1000;
+
+    ALTER TABLE stock_reservation_items ALTER COLUMN quantity TYPE BIGINT;
+    ALTER TABLE stock_reservation_items ADD COLUMN IF NOT EXISTS unit VARCHAR(10) NOT NULL DEFAULT 'piece';
+    UPDATE stock_reservation_items SET quantity = quantity * 1000;
+
+    ALTER TABLE order_details ALTER COLUMN quantity TYPE BIGINT;
+    UPDATE order_details SET quantity = quantity * 1000;
+
+    ALTER TABLE cart_items ALTER COLUMN quantity TYPE BIGINT;
+    UPDATE cart_items SET quantity = quantity * 1000;
+END $$;
+
+COMMIT;
@@ -0,0 +1,45 @@
+package tracing
+
+import (
+	"context"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+)
+
+// amqpHeaders carries trace context in the headers of a rabbitmq message.
+type amqpHeaders amqp.Table
+
+func (h amqpHeaders) Get(key string) string {
+	value, _ := h[key].(string)
+	return value
+}
+
+func (h amqpHeaders) Set(key, value string) {
+	h[key] = value
+}
+
+func (h amqpHeaders) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// InjectAMQP adds the trace context of ctx to headers, creating them when nil.
+func InjectAMQP(ctx context.Context, headers amqp.Table) amqp.Table {
+	if headers == nil {
+		headers = amqp.Table{}
+	}
+	otel.GetTextMapPropagator().Inject(ctx, amqpHeaders(headers))
+	return headers
+}
+
+// ExtractAMQP returns ctx with the trace context found in headers.
+func ExtractAMQP(ctx context.Context, headers amqp.Table) context.Context {
+	if headers == nil {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, amqpHeaders(headers))
+}
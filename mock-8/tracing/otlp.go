@@ -0,0 +1,285 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// otlpExporter posts spans to an OTLP/HTTP collector using the json encoding.
+type otlpExporter struct {
+	url    string
+	client *http.Client
+}
+
+func newOTLPExporter(endpoint string) *otlpExporter {
+	return &otlpExporter{
+		url:    strings.TrimSuffix(endpoint, "/") + "/v1/traces",
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (e *otlpExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(otlpRequest{ResourceSpans: toOTLPResourceSpans(spans)})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("otlp export: %s: %s", res.Status, msg)
+	}
+	io.Copy(io.Discard, res.Body)
+	return nil
+}
+
+func (e *otlpExporter) Shutdown(ctx context.Context) error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+// json mapping of opentelemetry/proto/collector/trace/v1.ExportTraceServiceRequest
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	SchemaURL  string           `json:"schemaUrl,omitempty"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpScopeSpans struct {
+	Scope     otlpScope  `json:"scope"`
+	Spans     []otlpSpan `json:"spans"`
+	SchemaURL string     `json:"schemaUrl,omitempty"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	TraceState        string         `json:"traceState,omitempty"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Events            []otlpEvent    `json:"events,omitempty"`
+	Links             []otlpLink     `json:"links,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpEvent struct {
+	Name         string         `json:"name"`
+	TimeUnixNano string         `json:"timeUnixNano"`
+	Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpLink struct {
+	TraceID    string         `json:"traceId"`
+	SpanID     string         `json:"spanId"`
+	Attributes []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string         `json:"stringValue,omitempty"`
+	BoolValue   *bool           `json:"boolValue,omitempty"`
+	IntValue    *string         `json:"intValue,omitempty"`
+	DoubleValue *float64        `json:"doubleValue,omitempty"`
+	ArrayValue  *otlpArrayValue `json:"arrayValue,omitempty"`
+}
+
+type otlpArrayValue struct {
+	Values []otlpValue `json:"values"`
+}
+
+// toOTLPResourceSpans groups spans by resource then by instrumentation scope
+func toOTLPResourceSpans(spans []sdktrace.ReadOnlySpan) []otlpResourceSpans {
+	var resources []otlpResourceSpans
+	resourceIndex := map[*resource.Resource]int{}
+	scopeIndex := map[*resource.Resource]map[instrumentation.Scope]int{}
+
+	for _, span := range spans {
+		res := span.Resource()
+		i, ok := resourceIndex[res]
+		if !ok {
+			i = len(resources)
+			resourceIndex[res] = i
+			scopeIndex[res] = map[instrumentation.Scope]int{}
+			resources = append(resources, otlpResourceSpans{
+				Resource:  otlpResource{Attributes: toOTLPAttributes(res.Attributes())},
+				SchemaURL: res.SchemaURL(),
+			})
+		}
+
+		scope := span.InstrumentationScope()
+		j, ok := scopeIndex[res][scope]
+		if !ok {
+			j = len(resources[i].ScopeSpans)
+			scopeIndex[res][scope] = j
+			resources[i].ScopeSpans = append(resources[i].ScopeSpans, otlpScopeSpans{
+				Scope:     otlpScope{Name: scope.Name, Version: scope.Version},
+				SchemaURL: scope.SchemaURL,
+			})
+		}
+
+		resources[i].ScopeSpans[j].Spans = append(resources[i].ScopeSpans[j].Spans, toOTLPSpan(span))
+	}
+
+	return resources
+}
+
+func toOTLPSpan(span sdktrace.ReadOnlySpan) otlpSpan {
+	sc := span.SpanContext()
+	s := otlpSpan{
+		TraceID:           sc.TraceID().String(),
+		SpanID:            sc.SpanID().String(),
+		TraceState:        sc.TraceState().String(),
+		Name:              span.Name(),
+		Kind:              int(span.SpanKind()),
+		StartTimeUnixNano: unixNano(span.StartTime()),
+		EndTimeUnixNano:   unixNano(span.EndTime()),
+		Attributes:        toOTLPAttributes(span.Attributes()),
+		Status:            toOTLPStatus(span.Status()),
+	}
+	if parent := span.Parent(); parent.HasSpanID() {
+		s.ParentSpanID = parent.SpanID().String()
+	}
+	for _, event := range span.Events() {
+		s.Events = append(s.Events, otlpEvent{
+			Name:         event.Name,
+			TimeUnixNano: unixNano(event.Time),
+			Attributes:   toOTLPAttributes(event.Attributes),
+		})
+	}
+	for _, link := range span.Links() {
+		s.Links = append(s.Links, otlpLink{
+			TraceID:    link.SpanContext.TraceID().String(),
+			SpanID:     link.SpanContext.SpanID().String(),
+			Attributes: toOTLPAttributes(link.Attributes),
+		})
+	}
+	return s
+}
+
+// otel and otlp number the status codes differently
+func toOTLPStatus(status sdktrace.Status) otlpStatus {
+	switch status.Code {
+	case codes.Ok:
+		return otlpStatus{Code: 1}
+	case codes.Error:
+		return otlpStatus{Code: 2, Message: status.Description}
+	}
+	return otlpStatus{}
+}
+
+func toOTLPAttributes(attrs []attribute.KeyValue) []otlpKeyValue {
+	if len(attrs) == 0 {
+		return nil
+	}
+	kvs := make([]otlpKeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		kvs = append(kvs, otlpKeyValue{Key: string(attr.Key), Value: toOTLPValue(attr.Value)})
+	}
+	return kvs
+}
+
+func toOTLPValue(v attribute.Value) otlpValue {
+	switch v.Type() {
+	case attribute.BOOL:
+		b := v.AsBool()
+		return otlpValue{BoolValue: &b}
+	case attribute.INT64:
+		return intValue(v.AsInt64())
+	case attribute.FLOAT64:
+		f := v.AsFloat64()
+		return otlpValue{DoubleValue: &f}
+	case attribute.BOOLSLICE:
+		var values []otlpValue
+		for _, b := range v.AsBoolSlice() {
+			b := b
+			values = append(values, otlpValue{BoolValue: &b})
+		}
+		return otlpValue{ArrayValue: &otlpArrayValue{Values: values}}
+	case attribute.INT64SLICE:
+		var values []otlpValue
+		for _, n := range v.AsInt64Slice() {
+			values = append(values, intValue(n))
+		}
+		return otlpValue{ArrayValue: &otlpArrayValue{Values: values}}
+	case attribute.FLOAT64SLICE:
+		var values []otlpValue
+		for _, f := range v.AsFloat64Slice() {
+			f := f
+			values = append(values, otlpValue{DoubleValue: &f})
+		}
+		return otlpValue{ArrayValue: &otlpArrayValue{Values: values}}
+	case attribute.STRINGSLICE:
+		var values []otlpValue
+		for _, s := range v.AsStringSlice() {
+			s := s
+			values = append(values, otlpValue{StringValue: &s})
+		}
+		return otlpValue{ArrayValue: &otlpArrayValue{Values: values}}
+	}
+	s := v.Emit()
+	return otlpValue{StringValue: &s}
+}
+
+// 64 bit integers are encoded as strings in otlp json
+func intValue(n int64) otlpValue {
+	s := strconv.FormatInt(n, 10)
+	return otlpValue{IntValue: &s}
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
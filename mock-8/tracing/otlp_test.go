@@ -0,0 +1,133 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestToOTLPResourceSpans(t *testing.T) {
+	res := resource.NewWithAttributes("https://opentelemetry.io/schemas/1.21.0", attribute.String("service.name", "api"))
+	start := time.Unix(1700000000, 0).UTC()
+	traceID := trace.TraceID{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10}
+	spanContext := func(spanID byte) trace.SpanContext {
+		return trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     trace.SpanID{0, 0, 0, 0, 0, 0, 0, spanID},
+			TraceFlags: trace.FlagsSampled,
+		})
+	}
+
+	stubs := tracetest.SpanStubs{
+		{
+			Name:        "GET /products",
+			SpanContext: spanContext(1),
+			SpanKind:    trace.SpanKindServer,
+			StartTime:   start,
+			EndTime:     start.Add(time.Second),
+			Attributes: []attribute.KeyValue{
+				attribute.Int("http.status_code", 200),
+				attribute.Bool("cached", false),
+				attribute.StringSlice("tags", []string{"tuna", "fresh"}),
+			},
+			Status:                 sdktrace.Status{Code: codes.Ok},
+			Resource:               res,
+			InstrumentationLibrary: instrumentation.Library{Name: "echo", Version: "1.0.0"},
+		},
+		{
+			Name:        "pb.ProductService/GetAllProducts",
+			SpanContext: spanContext(2),
+			Parent:      spanContext(1),
+			SpanKind:    trace.SpanKindClient,
+			StartTime:   start.Add(time.Millisecond),
+			EndTime:     start.Add(2 * time.Millisecond),
+			Events: []sdktrace.Event{
+				{Name: "message", Time: start.Add(time.Millisecond), Attributes: []attribute.KeyValue{attribute.Float64("size", 1.5)}},
+			},
+			Links: []sdktrace.Link{
+				{SpanContext: spanContext(3), Attributes: []attribute.KeyValue{attribute.Int64Slice("attempts", []int64{1, 2})}},
+			},
+			Status:                 sdktrace.Status{Code: codes.Error, Description: "unavailable"},
+			Resource:               res,
+			InstrumentationLibrary: instrumentation.Library{Name: "otelgrpc"},
+		},
+		{
+			Name:                   "db query",
+			SpanContext:            spanContext(4),
+			Parent:                 spanContext(1),
+			SpanKind:               trace.SpanKindInternal,
+			StartTime:              start,
+			EndTime:                start,
+			Resource:               res,
+			InstrumentationLibrary: instrumentation.Library{Name: "echo", Version: "1.0.0"},
+		},
+	}
+
+	got, err := json.Marshal(otlpRequest{ResourceSpans: toOTLPResourceSpans(stubs.Snapshots())})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"resourceSpans":[{
+		"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"api"}}]},
+		"scopeSpans":[
+			{"scope":{"name":"echo","version":"1.0.0"},"spans":[
+				{"traceId":"0102030405060708090a0b0c0d0e0f10","spanId":"0000000000000001","name":"GET /products","kind":2,
+				 "startTimeUnixNano":"1700000000000000000","endTimeUnixNano":"1700000001000000000",
+				 "attributes":[
+					{"key":"http.status_code","value":{"intValue":"200"}},
+					{"key":"cached","value":{"boolValue":false}},
+					{"key":"tags","value":{"arrayValue":{"values":[{"stringValue":"tuna"},{"stringValue":"fresh"}]}}}],
+				 "status":{"code":1}},
+				{"traceId":"0102030405060708090a0b0c0d0e0f10","spanId":"0000000000000004","parentSpanId":"0000000000000001","name":"db query","kind":1,
+				 "startTimeUnixNano":"1700000000000000000","endTimeUnixNano":"1700000000000000000",
+				 "status":{}}]},
+			{"scope":{"name":"otelgrpc"},"spans":[
+				{"traceId":"0102030405060708090a0b0c0d0e0f10","spanId":"0000000000000002","parentSpanId":"0000000000000001","name":"pb.ProductService/GetAllProducts","kind":3,
+				 "startTimeUnixNano":"1700000000001000000","endTimeUnixNano":"1700000000002000000",
+				 "events":[{"name":"message","timeUnixNano":"1700000000001000000","attributes":[{"key":"size","value":{"doubleValue":1.5}}]}],
+				 "links":[{"traceId":"0102030405060708090a0b0c0d0e0f10","spanId":"0000000000000003",
+					"attributes":[{"key":"attempts","value":{"arrayValue":{"values":[{"intValue":"1"},{"intValue":"2"}]}}}]}],
+				 "status":{"code":2,"message":"unavailable"}}]}],
+		"schemaUrl":"https://opentelemetry.io/schemas/1.21.0"}]}`
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, []byte(want)); err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != compact.String() {
+		t.Errorf("otlp json mismatch\n got: %s\nwant: %s", got, compact.String())
+	}
+}
+
+func TestToOTLPStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		status sdktrace.Status
+		want   string
+	}{
+		{"unset", sdktrace.Status{Code: codes.Unset}, `{}`},
+		{"ok", sdktrace.Status{Code: codes.Ok, Description: "ignored"}, `{"code":1}`},
+		{"error", sdktrace.Status{Code: codes.Error, Description: "boom"}, `{"code":2,"message":"boom"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(toOTLPStatus(tt.status))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"final_project-ftgo-h8/config"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "final_project-ftgo-h8"
+
+// Tracer returns the tracer used for the spans created by this module.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Setup installs the global tracer provider of service with the configured exporters
+// and the W3C trace context propagator. The returned func flushes pending spans,
+// it must run after every component creating spans is stopped.
+// With no exporter spans are not recorded but trace context is still propagated.
+func Setup(service string, cfg config.TracingConfig) (func(ctx context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	names := cfg.ExporterNames()
+	if len(names) == 0 {
+		return func(ctx context.Context) error { return nil }, nil
+	}
+
+	var closers []func() error
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(service))),
+	}
+	for _, name := range names {
+		var exporter sdktrace.SpanExporter
+		var err error
+		switch name {
+		case config.ExporterOTLP:
+			exporter = newOTLPExporter(cfg.OTLPEndpoint)
+		case config.ExporterStdout:
+			exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+		case config.ExporterFile:
+			// one json span per line, appended across restarts
+			var file *os.File
+			file, err = os.OpenFile(cfg.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+			if err == nil {
+				closers = append(closers, file.Close)
+				exporter, err = stdouttrace.New(stdouttrace.WithWriter(file))
+			}
+		default:
+			err = errors.New("unknown exporter")
+		}
+		if err != nil {
+			for _, close := range closers {
+				close()
+			}
+			return nil, fmt.Errorf("tracing exporter %s: %w", name, err)
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		for _, close := range closers {
+			err = errors.Join(err, close())
+		}
+		return err
+	}, nil
+}
+
+// TraceParent returns the w3c traceparent of the span in ctx, empty when there is none.
+// It keeps the trace of work that is stored and carried on later, like outbox messages.
+func TraceParent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	return carrier.Get("traceparent")
+}
+
+// WithTraceParent returns ctx with the remote span of traceparent as its parent.
+func WithTraceParent(ctx context.Context, traceParent string) context.Context {
+	if traceParent == "" {
+		return ctx
+	}
+	return propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier{"traceparent": traceParent})
+}
+
+// Detach returns a context that keeps the span of ctx but is never cancelled,
+// for cleanup that must finish after the request is gone.
+func Detach(ctx context.Context) context.Context {
+	return trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx))
+}